@@ -1,25 +1,35 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 )
 
 type Analyzer struct {
+	config      *Config
 	workerCount int
+	throttle    *FetchThrottle
 	workerInput chan string
 }
 
-func NewAnalyzer(workerCount int) *Analyzer {
+func NewAnalyzer(workerCount int, hostLimits HostLimits, config *Config) *Analyzer {
 	return &Analyzer{
+		config:      config,
 		workerCount: workerCount,
+		throttle:    NewFetchThrottle(hostLimits),
 		workerInput: make(chan string),
 	}
 }
 
-func (this *Analyzer) AnalyzeAll(paths []string) (fetches []*GitReport) {
+// AnalyzeAll reports on each of paths. Once ctx is done, any remaining paths
+// are reported as timed out without running any further commands.
+func (this *Analyzer) AnalyzeAll(ctx context.Context, paths []string) (fetches []*GitReport) {
 	go this.loadInputs(paths)
-	outputs := this.startWorkers()
+	outputs := this.startWorkers(ctx)
 	for fetch := range merge(outputs...) {
 		fetches = append(fetches, fetch)
 	}
@@ -36,15 +46,89 @@ func (this *Analyzer) loadInputs(paths []string) {
 	close(this.workerInput)
 }
 
-func (this *Analyzer) startWorkers() (outputs []chan *GitReport) {
+func (this *Analyzer) startWorkers(ctx context.Context) (outputs []chan *GitReport) {
 	for x := 0; x < this.workerCount; x++ {
 		output := make(chan *GitReport)
 		outputs = append(outputs, output)
-		go NewWorker(x, this.workerInput, output).Start()
+		go NewWorker(x, this.workerInput, output, this.throttle, this.config).Start(ctx)
 	}
 	return outputs
 }
 
+// FetchThrottle limits the number of fetches from each host at once (see
+// HostLimits), leaving the rest of the analysis unlimited.
+type FetchThrottle struct {
+	limits HostLimits
+	lock   sync.Mutex
+	slots  map[string]chan struct{} // by host, with room for its limit
+}
+
+func NewFetchThrottle(limits HostLimits) *FetchThrottle {
+	return &FetchThrottle{limits: limits, slots: make(map[string]chan struct{})}
+}
+
+// Acquire waits until a fetch from host may begin (or ctx is done) and
+// returns the func which signals the end of the fetch.
+func (this *FetchThrottle) Acquire(ctx context.Context, host string) (release func()) {
+	limit := this.limits.Limit(host)
+	if limit <= 0 {
+		return func() {}
+	}
+	this.lock.Lock()
+	slots, found := this.slots[host]
+	if !found {
+		slots = make(chan struct{}, limit)
+		this.slots[host] = slots
+	}
+	this.lock.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }
+	case <-ctx.Done():
+		return func() {}
+	}
+}
+
+// HostLimits caps the number of fetches from each host at once.
+// A limit of 0 means there is no limit.
+type HostLimits struct {
+	Default int
+	Hosts   map[string]int
+}
+
+// ParseHostLimits reads a comma-separated list where each item is either a
+// number (the limit of every host not listed) or host=number, ie. "4,git.example.com=2".
+func ParseHostLimits(value string) (limits HostLimits, err error) {
+	limits.Hosts = make(map[string]int)
+	for _, item := range splitList(value, ",") {
+		host, number, found := strings.Cut(item, "=")
+		if !found {
+			host, number = "", item
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(number))
+		if err != nil || limit < 0 {
+			return limits, fmt.Errorf("invalid limit: %q", item)
+		}
+		if host = strings.TrimSpace(host); host == "" {
+			limits.Default = limit
+		} else {
+			limits.Hosts[host] = limit
+		}
+	}
+	return limits, nil
+}
+
+func (this HostLimits) Limit(host string) int {
+	if host == "" {
+		return 0 // local remotes
+	}
+	if limit, found := this.Hosts[host]; found {
+		return limit
+	}
+	return this.Default
+}
+
 func merge(fannedOut ...chan *GitReport) chan *GitReport {
 	var waiter sync.WaitGroup
 	waiter.Add(len(fannedOut))
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var gitBranchesCommand = "git for-each-ref --format=%(refname:short)%09%(upstream:short)%09%(upstream:track) refs/heads" // ie. [feature	origin/feature	[ahead 1, behind 2]]
+
+// GitBranch is a local branch (other than the default branch) with an upstream.
+type GitBranch struct {
+	Name     string `json:"name"`
+	Upstream string `json:"upstream"`
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	Gone     bool   `json:"gone"`
+}
+
+// GitBranches compares each local branch with its upstream, as tracked
+// by 'git for-each-ref' (ie. '[ahead 1, behind 2]' or '[gone]').
+func (this *GitReport) GitBranches(ctx context.Context) {
+	out, err := execute(ctx, this.RepoPath, gitBranchesCommand)
+	if err != nil {
+		this.RevListError += this.failed(gitBranchesCommand, err)
+		return
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 || fields[1] == "" || fields[0] == this.Branch {
+			continue
+		}
+		branch := GitBranch{Name: fields[0], Upstream: fields[1]}
+		track := strings.Trim(fields[2], "[]")
+		for _, item := range strings.Split(track, ", ") {
+			key, value, _ := strings.Cut(item, " ")
+			switch key {
+			case "ahead":
+				branch.Ahead, _ = strconv.Atoi(value)
+			case "behind":
+				branch.Behind, _ = strconv.Atoi(value)
+			case "gone":
+				branch.Gone = true
+			}
+		}
+		branch.Gone = branch.Gone || this.deletedOnFetch(branch.Upstream)
+		this.Branches = append(this.Branches, branch)
+	}
+}
+
+// deletedOnFetch catches upstream branches that would have been pruned
+// (when the fetch is only a --dry-run).
+func (this *GitReport) deletedOnFetch(upstream string) bool {
+	for _, update := range this.Fetched {
+		if update.Flag == refUpdateDeleted && update.Ref == upstream {
+			return true
+		}
+	}
+	return false
+}
+
+func (this GitBranch) AheadMessage() string {
+	return fmt.Sprintf("The %s branch is %d commits ahead of %s.\n", this.Name, this.Ahead, this.Upstream)
+}
+
+func (this GitBranch) BehindMessage() string {
+	return fmt.Sprintf("The %s branch is %d commits behind %s.\n", this.Name, this.Behind, this.Upstream)
+}
+
+func (this GitBranch) GoneMessage() string {
+	return fmt.Sprintf("The upstream of the %s branch (%s) is gone.\n", this.Name, this.Upstream)
+}
+
+func (this *GitReport) upstreamGone() bool {
+	for _, branch := range this.Branches {
+		if branch.Gone {
+			return true
+		}
+	}
+	return false
+}
+
+// Progress summarizes the branch for the progress line, ie. 'feature +1/-2'.
+func (this GitBranch) Progress() string {
+	if this.Gone {
+		return this.Name + " gone"
+	}
+	return fmt.Sprintf("%s +%d/-%d", this.Name, this.Ahead, this.Behind)
+}
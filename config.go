@@ -10,20 +10,59 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"time"
+)
+
+const (
+	commandAnalyze = "analyze" // analyze and write the report, but don't review
+	commandReview  = "review"  // review (and journal) a saved report
+	commandJournal = "journal" // journal a saved report
 )
 
 type Config struct {
+	Command            string // blank (analyze, review and journal) or any of the commands above
+	SavedReportPath    string // the report to review or journal
+	Batch              bool
 	GitFetch           bool
 	GitRepositoryPaths []string
 	GitRepositoryRoots []string
+	GitRepositoryDepth int
+	GitRepositoryPrune []string
+	GitNestedScan      bool
+	GitSubmodules      bool
+	GitBranches        bool
+	IgnoredFileSize    int64
+	CommandTimeout     time.Duration
+	FetchRetries       int
+	Workers            int
+	HostLimits         HostLimits
+	AnalysisDeadline   time.Duration
 	GitGUILauncher     string
+	GUIBatchSize       int
 	OutputFilePath     string
+	ReportFormat       string
+	ReportFilePath     string
 	ReviewAhead        bool
 	ReviewBehind       bool
 	ReviewError        bool
 	ReviewFetched      bool
+	ReviewForced       bool
+	ReviewGone         bool
+	ReviewStashed      bool
+	ReviewPending      bool
+	ReviewDetached     bool
+	ReviewBisecting    bool
 	ReviewJournal      bool
 	ReviewMessy        bool
+	ReviewChanged      bool
+	ReviewConflicted   bool
+	ReviewTimedOut     bool
+	ReviewUnfetched    bool
+	JournalRules       []JournalRule
+	JournalFormat      string
+	SignOff            bool
+	FastForward        bool
+	Overrides          []RepositoryOverride
 }
 
 func ReadConfig(version string) *Config {
@@ -35,6 +74,7 @@ func ReadConfig(version string) *Config {
 
 	flags.Usage = func() {
 		_, _ = fmt.Fprintf(flags.Output(), "Usage of %s:\n\n", flags.Name())
+		_, _ = fmt.Fprintf(flags.Output(), "%s\n\n", usage)
 		_, _ = fmt.Fprintf(flags.Output(), "%s\n\n```\n", doc)
 		flags.PrintDefaults()
 		_, _ = fmt.Fprintln(flags.Output(), "```")
@@ -42,7 +82,28 @@ func ReadConfig(version string) *Config {
 
 	flags.StringVar(&config.GitGUILauncher,
 		"gui", "smerge", ""+
-			"The external git GUI application to use for visual reviews.\n"+
+			"The external git GUI application to use for visual reviews: a preset\n"+
+			"(smerge, gitk, tig, lazygit, code or github) or a command template\n"+
+			"with any of these placeholders: {path}, {branch} (the default\n"+
+			"branch), {remote} (ie. origin/master), {base} (the commit before\n"+
+			"the new commits), {head}, {range} ({base}..{head}) and {first} (the\n"+
+			"oldest new commit), ie. 'tig log {range}'. Repositories without\n"+
+			"new commits are opened with the template following a '|' (ie.\n"+
+			"'tig {range}|tig'), if any, or else with '<command> {path}'.\n"+
+			"The command runs within the repository; any other command without\n"+
+			"placeholders is passed the path of the repository.\n"+
+			"Specify 'builtin' to review commits and their diffs in the\n"+
+			"terminal instead (useful over SSH or on headless machines).\n"+
+			"-->",
+	)
+
+	flags.IntVar(&config.GUIBatchSize,
+		"gui-batch", 0, ""+
+			"When positive, the GUI is opened for this many repositories at a\n"+
+			"time (1 reviews one repository at a time). Each batch is announced\n"+
+			"(ie. [3/40]) and may be opened, skipped or revisited; the next batch\n"+
+			"is announced once the GUI of the current batch is closed. When 0,\n"+
+			"the GUI is opened for every repository without pause.\n"+
 			"-->",
 	)
 
@@ -50,7 +111,76 @@ func ReadConfig(version string) *Config {
 		"outfile", "SMARTY_REVIEW_LOG", ""+
 			"The path or name of the environment variable containing the\n"+
 			"path to your pre-existing code review file. If the file exists\n"+
-			"the final log entry will be appended to that file instead of stdout.\n"+
+			"the final log entry will be appended to that file instead of stdout\n"+
+			"(with -journal-format html, a new file is written beside it).\n"+
+			"-->",
+	)
+
+	flags.StringVar(&config.ReportFormat,
+		"format", "text", ""+
+			"The format of the analysis report: 'text' (no report, only the\n"+
+			"log and the review), 'json' (a single document) or 'ndjson' (one\n"+
+			"repository per line).\n"+
+			"-->",
+	)
+
+	flags.StringVar(&config.ReportFilePath,
+		"report", "", ""+
+			"The path of the file to which a json or ndjson analysis report\n"+
+			"is written (the file will be overwritten). When blank the report\n"+
+			"is written to stdout.\n"+
+			"-->",
+	)
+
+	flags.BoolVar(&config.Batch,
+		"batch", false, ""+
+			"When true, skip all prompts, GUI launches and the code review\n"+
+			"log entry. A summary is logged and the exit code is the sum of\n"+
+			"the following values for each category selected by -review\n"+
+			"that contains any repositories: 4 (e, t or u), 8 (m, w or c), 16 (a),\n"+
+			"32 (b), 64 (f, j or x), 128 (g, z, p, d or i). Exit codes with\n"+
+			"either of the lowest bits set mean gitreview itself failed: 1 (an\n"+
+			"error such as an invalid setting), 2 (invalid flags) or 130\n"+
+			"(interrupted).\n"+
+			"-->",
+	)
+
+	journalRules := flags.String(
+		"journal", defaultJournalRules, ""+
+			"A comma-separated list of rules selecting the repositories (by their\n"+
+			"remote url) to include in the code review log entry; where rules are\n"+
+			"formatted as 'host:<host>', 'owner:<owner>', 'url:<regexp>' or\n"+
+			"'text:<text>'. A repository is included if it matches any rule (or\n"+
+			"if all rules are exclusions) unless it matches an exclusion, which\n"+
+			"is a rule prefixed with '!' (ie. 'owner:smarty,!text:/forks/').\n"+
+			"-->",
+	)
+
+	flags.StringVar(&config.JournalFormat,
+		"journal-format", journalFormatText, ""+
+			"The format of the code review log entry: 'text' (the output of\n"+
+			"git fetch, etc.), 'markdown' (a section per repository with a\n"+
+			"table of linked commits) or 'html' (a standalone document).\n"+
+			"-->",
+	)
+
+	flags.BoolVar(&config.SignOff,
+		"sign-off", false, ""+
+			"When true, after each repository is reviewed you will be asked\n"+
+			"to approve, flag or mark for follow-up each commit under review\n"+
+			"(with an optional note). Decisions are included in the code\n"+
+			"review log entry.\n"+
+			"-->",
+	)
+
+	flags.BoolVar(&config.FastForward,
+		"fast-forward", false, ""+
+			"When true, once the review is concluded (rather than quit) the\n"+
+			"default branch of each reviewed repository that is behind is\n"+
+			"fast-forwarded to the reviewed commit (as with 'git pull --ff-only'),\n"+
+			"provided the repository is clean, not ahead, not detached, has no\n"+
+			"operation in progress and has the default branch checked out. Any\n"+
+			"others are reported.\n"+
 			"-->",
 	)
 
@@ -65,12 +195,111 @@ func ReadConfig(version string) *Config {
 		"roots", "CDPATH", ""+
 			"The name of the environment variable containing colon-separated\n"+
 			"path values to scan for any git repositories contained therein.\n"+
-			"Scanning is NOT recursive.\n"+
+			"Scanning is NOT recursive unless -depth is specified.\n"+
 			"NOTE: this flag will be ignored in the case that non-flag command\n"+
 			"line arguments representing paths to git repositories are provided.\n"+
 			"-->",
 	)
 
+	flags.IntVar(&config.GitRepositoryDepth,
+		"depth", 1, ""+
+			"How many directory levels below each root to scan for git\n"+
+			"repositories. The default (1) examines only the immediate\n"+
+			"children of each root; 0 means there is no limit.\n"+
+			"-->",
+	)
+
+	prune := flags.String(
+		"prune", "node_modules,vendor,.terraform", ""+
+			"A comma-separated list of directory name patterns (see filepath.Match)\n"+
+			"that will not be entered when scanning roots (the immediate children\n"+
+			"of each root are examined regardless).\n"+
+			"-->",
+	)
+
+	flags.BoolVar(&config.GitNestedScan,
+		"nested", false, ""+
+			"When true, continue scanning inside git repositories for nested\n"+
+			"repositories (only relevant when -depth is not 1).\n"+
+			"-->",
+	)
+
+	flags.BoolVar(&config.GitSubmodules,
+		"submodules", false, ""+
+			"When true, initialized submodules of each repository are analyzed\n"+
+			"(and reviewed) as repositories in their own right.\n"+
+			"-->",
+	)
+
+	flags.BoolVar(&config.GitBranches,
+		"branches", false, ""+
+			"When true, every local branch with an upstream (not just the default\n"+
+			"branch) is checked for being ahead of or behind its upstream, and\n"+
+			"fetches prune remote-tracking branches so that branches whose\n"+
+			"upstream was deleted are reported.\n"+
+			"-->",
+	)
+
+	flags.Int64Var(&config.IgnoredFileSize,
+		"ignored-size", 0, ""+
+			"When positive, ignored files at least this large (in bytes) are\n"+
+			"reported with the status of each repository (not as messy).\n"+
+			"-->",
+	)
+
+	flags.DurationVar(&config.CommandTimeout,
+		"timeout", 0, ""+
+			"The longest any single git command (ie. a fetch from an unreachable\n"+
+			"host) may run before it is interrupted and its repository is reported\n"+
+			"as timed out. Mind that large fetches can be slow but still working.\n"+
+			"0 (the default) means there is no limit.\n"+
+			"-->",
+	)
+
+	flags.DurationVar(&config.AnalysisDeadline,
+		"deadline", 0, ""+
+			"The longest the analysis of all repositories may run. Repositories\n"+
+			"not analyzed by then are reported as timed out and the review\n"+
+			"continues with the rest. 0 means there is no limit.\n"+
+			"-->",
+	)
+
+	flags.IntVar(&config.FetchRetries,
+		"fetch-retries", 2, ""+
+			"How many times to retry a fetch that failed for a transient reason\n"+
+			"(network trouble or a locked ref), waiting 1s, then 2s, etc. between\n"+
+			"attempts. Fetches that still fail are reported in the summary, but\n"+
+			"not reviewed as errors (unlike auth, not-found and other failures)\n"+
+			"unless -review includes (u).\n"+
+			"-->",
+	)
+
+	flags.IntVar(&config.Workers,
+		"workers", 16, ""+
+			"The number of repositories to analyze at once.\n"+
+			"-->",
+	)
+
+	hostLimits := flags.String(
+		"host-workers", "0", ""+
+			"The most fetches from the same remote host (ie. github.com) at once,\n"+
+			"so as not to trip any rate limits (the rest of the analysis isn't\n"+
+			"limited). Limits for particular hosts follow as a comma-separated\n"+
+			"list of host=number (ie. '0,git.example.com=2'). 0 (the default)\n"+
+			"means there is no limit.\n"+
+			"-->",
+	)
+
+	configPath := flags.String(
+		"config", "", ""+
+			"The path of the config file, which sets the defaults of any of these\n"+
+			"flags along with the directories to scan, the repositories to examine\n"+
+			"and per-repository overrides (see above). When blank, the file at\n"+
+			"$XDG_CONFIG_HOME/gitreview/config.toml (or else at\n"+
+			"~/.config/gitreview/config.toml) is read, if it exists.\n"+
+			"-->",
+	)
+
 	repoList := flags.String(
 		"roots-file", "", ""+
 			"A colon-separated list of file paths, where each file contains a\n"+
@@ -79,26 +308,106 @@ func ReadConfig(version string) *Config {
 	)
 
 	review := flags.String(
-		"review", "abejm", ""+
-			"Letter code of repository statuses to review; where (a) is ahead,\n"+
-			"origin/master (b) is behind origin/master, (e) has git errors,\n"+
-			"(f) has new fetched contents, and (m) is messy with uncommitted\n"+
-			"changes. (j) is like (f) except only 'smarty' repositories\n"+
+		"review", "abejmxpt", ""+
+			"Letter code of repository statuses to review; where (a) is ahead\n"+
+			"of <remote>/<branch>, (b) is behind <remote>/<branch>, (e) has\n"+
+			"git errors, (f) has new fetched contents, (m) is messy with\n"+
+			"uncommitted changes, (w) is like (m) except that untracked files\n"+
+			"are not considered, (c) has conflicts, (x) had its default branch\n"+
+			"force-pushed, and (g) has branches whose upstream is gone (see\n"+
+			"-branches). Forgotten states are (z) has stash entries, (p) has\n"+
+			"a rebase, merge, cherry-pick, revert or am in progress, (d) has a\n"+
+			"detached HEAD, and (i) has a bisect in progress. (t) had a git\n"+
+			"command time out (see -timeout and -deadline), and (u) could not be\n"+
+			"fetched for a transient reason, even after retries (see\n"+
+			"-fetch-retries).\n"+
+			"(j) is like (f) except only repositories selected by -journal\n"+
 			"are considered\n"+
 			"-->",
 	)
 
-	_ = flags.Parse(os.Args[1:])
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case commandAnalyze, commandReview, commandJournal:
+			config.Command, args = args[0], args[1:]
+		}
+	}
+	_ = flags.Parse(args)
+
+	file := config.applyConfigFile(flags, *configPath)
 
 	config.ReviewAhead = strings.ContainsAny(*review, "aA")
 	config.ReviewBehind = strings.ContainsAny(*review, "bB")
 	config.ReviewError = strings.ContainsAny(*review, "eE")
 	config.ReviewFetched = strings.ContainsAny(*review, "fF")
+	config.ReviewForced = strings.ContainsAny(*review, "xX")
+	config.ReviewGone = strings.ContainsAny(*review, "gG")
+	config.ReviewStashed = strings.ContainsAny(*review, "zZ")
+	config.ReviewPending = strings.ContainsAny(*review, "pP")
+	config.ReviewDetached = strings.ContainsAny(*review, "dD")
+	config.ReviewBisecting = strings.ContainsAny(*review, "iI")
 	config.ReviewJournal = strings.ContainsAny(*review, "jJ")
 	config.ReviewMessy = strings.ContainsAny(*review, "mM")
+	config.ReviewChanged = strings.ContainsAny(*review, "wW")
+	config.ReviewConflicted = strings.ContainsAny(*review, "cC")
+	config.ReviewTimedOut = strings.ContainsAny(*review, "tT")
+	config.ReviewUnfetched = strings.ContainsAny(*review, "uU")
+
+	rules, err := ParseJournalRules(*journalRules)
+	if err != nil {
+		log.Fatalf("Invalid -journal: %v", err)
+	}
+	config.JournalRules = rules
+
+	config.HostLimits, err = ParseHostLimits(*hostLimits)
+	if err != nil {
+		log.Fatalf("Invalid -host-workers: %v", err)
+	}
+	if config.Workers < 1 {
+		log.Fatalf("Invalid -workers: %d", config.Workers)
+	}
+
+	switch config.JournalFormat {
+	case journalFormatText, journalFormatMarkdown, journalFormatHTML:
+	default:
+		log.Fatalf("Unrecognized journal format: %s", config.JournalFormat)
+	}
+
+	switch config.ReportFormat {
+	case reportFormatText, reportFormatJSON, reportFormatNDJSON:
+	default:
+		log.Fatalf("Unrecognized report format: %s", config.ReportFormat)
+	}
+
+	commandTimeout = config.CommandTimeout
+
+	if config.Command == commandReview || config.Command == commandJournal {
+		if flags.NArg() != 1 {
+			log.Fatalf("Usage: gitreview %s [flags] <report>", config.Command)
+		}
+		config.SavedReportPath = flags.Arg(0)
+		return config // there are no repositories to find (or fetch)
+	}
+	if config.Command == commandAnalyze && config.ReportFormat == reportFormatText {
+		config.ReportFormat = reportFormatJSON
+	}
 
 	config.GitRepositoryPaths = flags.Args()
+	config.GitRepositoryPrune = splitList(*prune, ",")
 	roots := strings.Split(os.Getenv(*gitRoots), ":")
+	if len(file.Directories) > 0 && !isFlagSet(flags, "roots") {
+		roots = nil
+		for _, directory := range file.Directories {
+			roots = append(roots, config.tryPaths(directory, nil))
+		}
+	}
+	if len(file.Repositories) > 0 && flags.NArg() == 0 {
+		for _, repository := range file.Repositories {
+			config.GitRepositoryPaths = append(config.GitRepositoryPaths, config.tryPaths(repository, roots))
+		}
+		log.Printf("Added %d repositories from file: %s", len(file.Repositories), file.Path)
+	}
 
 	if len(*repoList) > 0 {
 		list := strings.Split(*repoList, ";")
@@ -119,6 +428,49 @@ func ReadConfig(version string) *Config {
 	return config
 }
 
+// applyConfigFile sets each flag named in the config file (see ConfigFile)
+// which wasn't set on the command line.
+func (this *Config) applyConfigFile(flags *flag.FlagSet, path string) *ConfigFile {
+	required := path != ""
+	if !required {
+		path = defaultConfigFilePath()
+	}
+	file, err := ReadConfigFile(this.tryPaths(path, nil), required)
+	if err != nil {
+		log.Fatalln("Could not read config file:", err)
+	}
+	for _, name := range mapKeys(file.Values) {
+		if name == "config" || flags.Lookup(name) == nil {
+			log.Fatalf("Unrecognized setting in config file: [%s] %s", file.Path, name)
+		}
+		if isFlagSet(flags, name) {
+			continue
+		}
+		if err = flags.Set(name, file.Values[name]); err != nil {
+			log.Fatalf("Invalid setting in config file: [%s] %s: %v", file.Path, name, err)
+		}
+	}
+	this.Overrides = file.Overrides
+	return file
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) (set bool) {
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+func splitList(value, separator string) (items []string) {
+	for _, item := range strings.Split(value, separator) {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (this *Config) OpenOutputWriter() io.WriteCloser {
 	this.OutputFilePath = strings.TrimSpace(this.OutputFilePath)
 	if this.OutputFilePath == "" {
@@ -134,6 +486,9 @@ func (this *Config) OpenOutputWriter() io.WriteCloser {
 	}
 
 	stat, err := os.Stat(path)
+	if err == nil && this.JournalFormat == journalFormatHTML {
+		return openHTMLJournal(path, stat.Mode())
+	}
 	if err == nil && err != os.ErrNotExist {
 		file, err2 := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, stat.Mode())
 		if err2 == nil {
@@ -148,6 +503,37 @@ func (this *Config) OpenOutputWriter() io.WriteCloser {
 	return os.Stdout
 }
 
+// openHTMLJournal creates a new file beside the code review file at path (ie.
+// reviews-2006-01-02-150405.html for reviews.txt), as a standalone HTML
+// document can't be appended to another.
+func openHTMLJournal(path string, mode os.FileMode) io.WriteCloser {
+	name := strings.TrimSuffix(path, filepath.Ext(path)) + time.Now().Format("-2006-01-02-150405") + ".html"
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		log.Printf("Could not create file: [%s] Error: %v", name, err)
+		log.Println("Final report will be written to stdout.")
+		return os.Stdout
+	}
+	log.Println("Final report will be written to", name)
+	return file
+}
+
+func (this *Config) OpenReportWriter() io.WriteCloser {
+	if this.ReportFilePath == "" {
+		return nopCloser{Writer: os.Stdout}
+	}
+	file, err := os.Create(this.ReportFilePath)
+	if err != nil {
+		log.Fatalf("Could not create report file: [%s] Error: %v", this.ReportFilePath, err)
+	}
+	log.Println("Analysis report will be written to", this.ReportFilePath)
+	return file
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
 func (this *Config) handleRepoFile(path string, prefixes []string) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -214,11 +600,12 @@ requests at our sole and absolute discretion.
 gitreview facilitates visual inspection (code review) of git
 repositories that meet any of the following criteria:
 
-1. New content was fetched
-2. Behind origin/<default-branch>
-3. Ahead of origin/<default-branch>
-4. Messy (have uncommitted state)
+1. New content was fetched (or arrived some other way since the last review)
+2. Behind <remote>/<default-branch>
+3. Ahead of <remote>/<default-branch>
+4. Messy (have uncommitted state, stashes, or an unfinished rebase, etc.)
 5. Throw errors for the required git operations (listed below)
+6. Had the history of their default branch rewritten (force-pushed)
 
 We use variants of the following commands to ascertain the
 status of each repository:
@@ -227,7 +614,9 @@ status of each repository:
 - ''git status''           (shows uncommitted files)
 - ''git fetch''            (finds new commits/tags/branches)
 - ''git rev-list''         (lists commits behind/ahead-of <default-branch>)
+- ''git ls-remote''        (finds the default branch, when not already known)
 - ''git config --get ...'' (show config parameters of a repo)
+- ''git update-ref''       (records the last reviewed commit)
 
 ...all of which should be safe enough. 
 
@@ -235,13 +624,55 @@ Each repository that meets any criteria above will be
 presented for review. After all reviews are complete a
 concatenated report of all output from ''git fetch'' for
 repositories that were behind their origin is printed to
-stdout. Only repositories with "smarty" in their
-path are included in this report.
+stdout. Only repositories selected by the -journal flag (by default,
+those with "smarty" in their remote url) are included in this report.
+
+Once the review is concluded, the commit at <remote>/<default-branch> is
+recorded as reviewed in the ''refs/review/last'' ref of each reviewed
+repository. Commits that arrive after that point are presented for review
+on subsequent runs, even if they were fetched by some other means (ie.
+''git pull''). Any analysis (-batch included) of a repository that lacks
+that ref records the commit <remote>/<default-branch> was at before the
+fetch, so the commits just fetched remain to be reviewed.
 
 Repositories are identified for consideration from path values
 supplied as non-flag command line arguments or via the roots
 flag (see details below).
 
+Subcommands:
+
+Without a subcommand, gitreview analyzes, reviews and journals in one go.
+These steps can also be run separately, ie. to analyze (and fetch) early in
+the morning via a scheduler and to review at some later time:
+
+- ''gitreview analyze'' analyzes and writes the report (see -format and
+  -report; json is the default format) and a summary, but doesn't review.
+- ''gitreview review <report>'' reviews the repositories of a saved report
+  and prints the code review log entry, all without analyzing again.
+- ''gitreview journal <report>'' only prints the code review log entry
+  of a saved report (and records the review markers of the repositories
+  included in it).
+
+Flags precede the report, ie. ''gitreview review -gui builtin report.json''.
+
+
+Config File:
+
+Any flag (by name) can be set in a config file (see -config) written in
+(a subset of) TOML, which is handy for sharing settings via a dotfiles
+repository. Flags on the command line take precedence over the file.
+
+    gui = "builtin"
+    review = "abejmxpt"
+    journal = ["owner:smarty", "!text:/forks/"]  # lists are joined with commas
+
+    directories = ["~/src", "~/work"]   # scanned instead of -roots
+    repositories = ["~/dotfiles"]       # examined (without any scan)
+
+    [repository."~/src/vendor/*"]       # overrides review.* git config
+    omit = true                         # also: skip, journal, remote, branch
+
+
 Installation:
 
     go get -u github.com/smarty/gitreview
@@ -266,17 +697,62 @@ to the repository. The following command will produce this result:
     git config --add review.omit true
 
 
+Worktrees and Submodules:
+
+Linked worktrees (created with ''git worktree add'') share the object store
+of their main repository, so they are analyzed once, as part of that
+repository. Any worktree with uncommitted changes is presented for review
+on its own. Initialized submodules are only analyzed when the -submodules
+flag is set.
+
+
+Journaling Repositories:
+
+The -journal flag decides which repositories are included in the final
+report. Regardless of that flag, a repository can be included (or
+excluded) by adding a config variable to the repository:
+
+    git config --add review.journal true
+
+
+Specifying the comparison remote:
+
+This tool compares the default branch of each repository with the same
+branch on the remote of its upstream (see ''git branch --set-upstream-to''),
+or else on the ''origin'' remote. If you work in a fork and would rather
+review what has been pushed to the canonical repository (ie. ''upstream''),
+run the following command (a missing remote is reported as an error):
+
+	git config --add review.remote <remote-name>
+
+
 Specifying the ''default'' branch:
 
-This tool assumes that the default branch of all repositories is ''master''.
-If a repository uses a non-standard default branch (ie. ''main'', ''trunk'')
-and you want this tool to focus  reviews on commits pushed to that branch
-instead, run the following command:
+This tool resolves the default branch of each repository from the HEAD of
+its remote (''refs/remotes/<remote>/HEAD'', or else ''git ls-remote --symref''),
+falling back to ''master'' when neither is available. If you want this tool
+to focus reviews on commits pushed to some other branch instead, run the
+following command:
 
 	git config --add review.branch <branch-name>
 
 
+Maintenance Actions:
+
+Before the review begins, enter ''m'' for a menu of chores to carry out in
+any of the analyzed repositories: pruning stale remote-tracking branches,
+deleting local branches merged into the default branch, running
+''git gc --auto'', or setting review.skip, review.omit or review.branch.
+The commands of each action are previewed (a dry run) and only run once
+confirmed.
+
+
 CLI Flags:
 `
 
+const usage = `    gitreview [flags] [repository ...]
+    gitreview analyze [flags] [repository ...]
+    gitreview review [flags] <report>
+    gitreview journal [flags] <report>`
+
 var doc = strings.ReplaceAll(strings.TrimSpace(rawDoc), "''", "`")
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConfigFile is the content of the config file (see defaultConfigFilePath),
+// which is written in a subset of TOML, ie:
+//
+//	# any flag, by name (lists are joined with commas)
+//	gui = "builtin"
+//	review = "abejmxpt"
+//	journal = ["owner:smarty", "!text:/forks/"]
+//
+//	directories = ["~/src", "~/work"]     # scanned instead of -roots
+//	repositories = ["~/dotfiles"]         # examined along with those of -roots-file
+//
+//	[repository."~/src/vendor/*"]         # overrides the review.* git config
+//	omit = true
+//
+// Flags (and paths) on the command line take precedence over the file.
+type ConfigFile struct {
+	Path         string
+	Values       map[string]string // by flag name
+	Directories  []string
+	Repositories []string
+	Overrides    []RepositoryOverride
+}
+
+// RepositoryOverride replaces the review.* git config (skip, omit, journal,
+// remote and branch) of each repository whose path matches the pattern
+// (see filepath.Match).
+type RepositoryOverride struct {
+	Pattern  string
+	Settings map[string]string // ie. review.omit=true
+}
+
+var repositoryOverrideSettings = []string{"skip", "omit", "journal", "remote", "branch"}
+
+func defaultConfigFilePath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gitreview", "config.toml")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gitreview", "config.toml")
+}
+
+// ReadConfigFile reads the file at path. A missing file is not an error
+// unless required is set.
+func ReadConfigFile(path string, required bool) (*ConfigFile, error) {
+	file := &ConfigFile{Path: path, Values: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tables, err := parseTOML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, table := range tables {
+		if err = file.add(table); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return file, nil
+}
+
+func (this *ConfigFile) add(table tomlTable) error {
+	if len(table.name) == 0 {
+		for _, key := range table.keys {
+			value := table.values[key]
+			switch key {
+			case "directories":
+				this.Directories = append(this.Directories, value.list()...)
+			case "repositories":
+				this.Repositories = append(this.Repositories, value.list()...)
+			default:
+				this.Values[key] = strings.Join(value.list(), ",")
+			}
+		}
+		return nil
+	}
+	if len(table.name) != 2 || table.name[0] != "repository" {
+		return fmt.Errorf("line %d: unrecognized table: [%s]", table.line, strings.Join(table.name, "."))
+	}
+	override := RepositoryOverride{Pattern: table.name[1], Settings: make(map[string]string)}
+	for _, key := range table.keys {
+		if !contains(repositoryOverrideSettings, key) {
+			return fmt.Errorf("line %d: unrecognized repository setting: %s", table.line, key)
+		}
+		override.Settings["review."+key] = strings.Join(table.values[key].list(), ",")
+	}
+	this.Overrides = append(this.Overrides, override)
+	return nil
+}
+
+// RepositoryOverrides merges the settings of every override matching path
+// (later overrides win).
+func (this *Config) RepositoryOverrides(path string) map[string]string {
+	path, _ = filepath.Abs(path)
+	var settings map[string]string
+	for _, override := range this.Overrides {
+		pattern := this.tryPaths(override.Pattern, nil)
+		if matched, _ := filepath.Match(pattern, path); !matched && pattern != path {
+			continue
+		}
+		if settings == nil {
+			settings = make(map[string]string)
+		}
+		for key, value := range override.Settings {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// tomlTable is a [table] of key/value pairs (the unnamed table holds the keys
+// preceding any table header).
+type tomlTable struct {
+	name   []string
+	line   int
+	keys   []string // in order of appearance
+	values map[string]tomlValue
+}
+
+// tomlValue is a string, boolean or integer (all kept as text) or an array thereof.
+type tomlValue struct {
+	text  string
+	items []string
+	array bool
+}
+
+func (this tomlValue) list() []string {
+	if this.array {
+		return this.items
+	}
+	return []string{this.text}
+}
+
+// parseTOML supports comments, [tables] (with quoted or dotted names), and
+// keys with string, boolean, integer and (possibly multi-line) array values.
+func parseTOML(data string) (tables []tomlTable, err error) {
+	tables = append(tables, tomlTable{values: make(map[string]tomlValue)})
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: unsupported table header: %s", number, line)
+			}
+			name, err := splitTOMLKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number, err)
+			}
+			tables = append(tables, tomlTable{name: name, line: number, values: make(map[string]tomlValue)})
+			continue
+		}
+
+		key, raw, found := cutTOMLKey(line)
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key = value: %s", number, line)
+		}
+		for strings.HasPrefix(raw, "[") && !balancedTOMLArray(raw) && i+1 < len(lines) {
+			i++
+			raw += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+		}
+		value, err := parseTOMLValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		table := &tables[len(tables)-1]
+		if _, duplicate := table.values[key]; duplicate {
+			return nil, fmt.Errorf("line %d: duplicate key: %s", number, key)
+		}
+		table.keys = append(table.keys, key)
+		table.values[key] = value
+	}
+	return tables, nil
+}
+
+// stripTOMLComment removes any '#' comment which isn't inside a string.
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == 0 && c == '#':
+			return line[:i]
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case c == quote:
+			quote = 0
+		}
+	}
+	return line
+}
+
+func balancedTOMLArray(raw string) bool {
+	depth, quote := 0, byte(0)
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; {
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth == 0
+}
+
+func cutTOMLKey(line string) (key, value string, found bool) {
+	if strings.HasPrefix(line, `"`) {
+		end := strings.Index(line[1:], `"`)
+		if end < 0 {
+			return "", "", false
+		}
+		key, line = line[1:end+1], line[end+2:]
+		_, value, found = strings.Cut(line, "=")
+		return key, strings.TrimSpace(value), found
+	}
+	key, value, found = strings.Cut(line, "=")
+	return strings.TrimSpace(key), strings.TrimSpace(value), found && strings.TrimSpace(key) != ""
+}
+
+// splitTOMLKey splits a dotted table name, ie. repository."~/src/*".
+func splitTOMLKey(name string) (parts []string, err error) {
+	name = strings.TrimSpace(name)
+	for name != "" {
+		var part string
+		if name[0] == '"' || name[0] == '\'' {
+			end := strings.IndexByte(name[1:], name[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated table name: %s", name)
+			}
+			part, name = name[1:end+1], name[end+2:]
+		} else {
+			part, name, _ = strings.Cut(name, ".")
+			part = strings.TrimSpace(part)
+			name = "." + name
+		}
+		parts = append(parts, part)
+		name = strings.TrimPrefix(strings.TrimSpace(name), ".")
+		name = strings.TrimSpace(name)
+	}
+	return parts, nil
+}
+
+func parseTOMLValue(raw string) (value tomlValue, err error) {
+	if !strings.HasPrefix(raw, "[") {
+		value.text, raw, err = parseTOMLScalar(raw)
+		if err == nil && strings.TrimSpace(raw) != "" {
+			err = fmt.Errorf("unexpected text after value: %s", raw)
+		}
+		return value, err
+	}
+	value.array = true
+	raw = strings.TrimSpace(raw[1:])
+	for {
+		if strings.HasPrefix(raw, "]") {
+			raw = raw[1:]
+			break
+		}
+		var item string
+		if item, raw, err = parseTOMLScalar(raw); err != nil {
+			return value, err
+		}
+		value.items = append(value.items, item)
+		raw = strings.TrimSpace(raw)
+		if strings.HasPrefix(raw, ",") {
+			raw = strings.TrimSpace(raw[1:])
+		} else if !strings.HasPrefix(raw, "]") {
+			return value, fmt.Errorf("expected ',' or ']' in array: %s", raw)
+		}
+	}
+	if strings.TrimSpace(raw) != "" {
+		return value, fmt.Errorf("unexpected text after array: %s", raw)
+	}
+	return value, nil
+}
+
+// parseTOMLScalar parses the string, boolean or integer at the start of raw
+// and returns it as text, along with the rest of raw.
+func parseTOMLScalar(raw string) (text, rest string, err error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string: %s", raw)
+		}
+		return raw[1 : end+1], raw[end+2:], nil
+	case strings.HasPrefix(raw, `"`):
+		for i := 1; i < len(raw); i++ {
+			if raw[i] == '\\' {
+				i++
+			} else if raw[i] == '"' {
+				text, err = strconv.Unquote(raw[:i+1])
+				return text, raw[i+1:], err
+			}
+		}
+		return "", "", fmt.Errorf("unterminated string: %s", raw)
+	}
+	end := strings.IndexAny(raw, ",] \t")
+	if end < 0 {
+		end = len(raw)
+	}
+	text, rest = raw[:end], raw[end:]
+	if text == "true" || text == "false" {
+		return text, rest, nil
+	}
+	if _, err = strconv.ParseInt(strings.ReplaceAll(text, "_", ""), 10, 64); err != nil {
+		return "", "", fmt.Errorf("unsupported value: %s", text)
+	}
+	return strings.ReplaceAll(text, "_", ""), rest, nil
+}
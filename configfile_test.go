@@ -0,0 +1,150 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data string
+		want []tomlTable
+		err  bool
+	}{
+		{
+			name: "keys with comments",
+			data: "# defaults\n" +
+				"gui = \"builtin\"  # the terminal review\n" +
+				"workers = 1_000\n" +
+				"batch = true\n" +
+				"\"journal-format\" = 'markdown'\n",
+			want: []tomlTable{{
+				keys: []string{"gui", "workers", "batch", "journal-format"},
+				values: map[string]tomlValue{
+					"gui":            {text: "builtin"},
+					"workers":        {text: "1000"},
+					"batch":          {text: "true"},
+					"journal-format": {text: "markdown"},
+				},
+			}},
+		},
+		{
+			name: "multi-line array",
+			data: "directories = [\n" +
+				"  \"~/src\",   # personal\n" +
+				"  '~/work',\n" +
+				"]\n" +
+				"journal = [\"owner:smarty\", \"!text:#forks\"]\n",
+			want: []tomlTable{{
+				keys: []string{"directories", "journal"},
+				values: map[string]tomlValue{
+					"directories": {items: []string{"~/src", "~/work"}, array: true},
+					"journal":     {items: []string{"owner:smarty", "!text:#forks"}, array: true},
+				},
+			}},
+		},
+		{
+			name: "quoted table names",
+			data: "[repository.\"~/src/vendor/*\"]\n" +
+				"omit = true\n" +
+				"\n" +
+				"[ repository . '~/a.b' ]\n" +
+				"branch = \"main\"\n",
+			want: []tomlTable{
+				{values: map[string]tomlValue{}},
+				{
+					name:   []string{"repository", "~/src/vendor/*"},
+					line:   1,
+					keys:   []string{"omit"},
+					values: map[string]tomlValue{"omit": {text: "true"}},
+				},
+				{
+					name:   []string{"repository", "~/a.b"},
+					line:   4,
+					keys:   []string{"branch"},
+					values: map[string]tomlValue{"branch": {text: "main"}},
+				},
+			},
+		},
+		{name: "duplicate key", data: "gui = \"a\"\ngui = \"b\"\n", err: true},
+		{name: "missing value", data: "gui\n", err: true},
+		{name: "array of tables", data: "[[repository]]\n", err: true},
+		{name: "unterminated table name", data: "[repository.\"~/src]\n", err: true},
+		{name: "unterminated array", data: "roots = [\"a\",\n\"b\"\n", err: true},
+		{name: "unsupported value", data: "deadline = 1.5\n", err: true},
+		{name: "text after value", data: "gui = \"a\" \"b\"\n", err: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTOML(test.data)
+			if test.err {
+				if err == nil {
+					t.Errorf("parseTOML() = %#v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTOML() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseTOML() =\n%#v\nwant\n%#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLScalar(t *testing.T) {
+	for _, test := range []struct {
+		raw  string
+		text string
+		rest string
+		err  bool
+	}{
+		{raw: `"plain"`, text: "plain"},
+		{raw: `"with \"escapes\"\t", "next"`, text: "with \"escapes\"\t", rest: `, "next"`},
+		{raw: `'C:\literal'] `, text: `C:\literal`, rest: "]"},
+		{raw: "false,", text: "false", rest: ","},
+		{raw: "  42 ", text: "42"},
+		{raw: "-1_024]", text: "-1024", rest: "]"},
+		{raw: `"unterminated`, err: true},
+		{raw: `'unterminated`, err: true},
+		{raw: "yes", err: true},
+	} {
+		text, rest, err := parseTOMLScalar(test.raw)
+		if test.err {
+			if err == nil {
+				t.Errorf("parseTOMLScalar(%q) = %q, want an error", test.raw, text)
+			}
+			continue
+		}
+		if err != nil || text != test.text || rest != test.rest {
+			t.Errorf("parseTOMLScalar(%q) = %q, %q, %v; want %q, %q", test.raw, text, rest, err, test.text, test.rest)
+		}
+	}
+}
+
+func TestSplitTOMLKey(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want []string
+		err  bool
+	}{
+		{name: "repository", want: []string{"repository"}},
+		{name: "a.b.c", want: []string{"a", "b", "c"}},
+		{name: ` repository . "~/src/*" `, want: []string{"repository", "~/src/*"}},
+		{name: `repository."~/dotted.name"`, want: []string{"repository", "~/dotted.name"}},
+		{name: `repository.'~/"quoted"'`, want: []string{"repository", `~/"quoted"`}},
+		{name: `repository."~/src`, err: true},
+	} {
+		got, err := splitTOMLKey(test.name)
+		if test.err {
+			if err == nil {
+				t.Errorf("splitTOMLKey(%q) = %q, want an error", test.name, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitTOMLKey(%q) = %q, %v; want %q", test.name, got, err, test.want)
+		}
+	}
+}
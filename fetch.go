@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	refUpdateFastForward = "fast-forward"
+	refUpdateForced      = "forced"
+	refUpdateNewBranch   = "new-branch"
+	refUpdateNewTag      = "new-tag"
+	refUpdateNewRef      = "new-ref"
+	refUpdateTagUpdate   = "tag-update"
+	refUpdateDeleted     = "deleted"
+	refUpdateRejected    = "rejected"
+	refUpdateUpToDate    = "up-to-date"
+)
+
+// GitRefUpdate is a single ref update reported by 'git fetch', ie:
+//
+//	  7761a97..1bbecb6  master     -> origin/master
+//	+ 1bbecb6...a2f7d81 feature    -> origin/feature  (forced update)
+//	* [new tag]         v1.2.3     -> v1.2.3
+//	- [deleted]         (none)     -> origin/old-feature
+type GitRefUpdate struct {
+	Flag      string `json:"flag"`
+	OldCommit string `json:"old,omitempty"`
+	NewCommit string `json:"new,omitempty"`
+	Ref       string `json:"ref"`
+	RemoteRef string `json:"remote_ref,omitempty"`
+}
+
+// parseGitFetch extracts ref updates from the (combined) output of 'git fetch'.
+// Each update line is formatted as ' <flag> <summary> <from> -> <to> [(<reason>)]'.
+func parseGitFetch(out string) (updates []GitRefUpdate) {
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(line) < 3 || line[0] != ' ' || !strings.Contains(line, gitFetchPendingReview) {
+			continue
+		}
+		flag, rest := line[1], strings.TrimSpace(line[2:])
+
+		summary := ""
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				continue
+			}
+			summary, rest = rest[:end+1], rest[end+1:]
+		} else {
+			summary, rest, _ = strings.Cut(rest, " ")
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) < 3 || fields[1] != gitFetchPendingReview {
+			continue
+		}
+		update := GitRefUpdate{
+			Flag:      refUpdateFlag(flag, summary),
+			Ref:       fields[2],
+			RemoteRef: fields[0],
+		}
+		if update.RemoteRef == "(none)" {
+			update.RemoteRef = ""
+		}
+		if old, new, found := strings.Cut(strings.Replace(summary, "...", "..", 1), ".."); found {
+			update.OldCommit, update.NewCommit = old, new
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+func refUpdateFlag(flag byte, summary string) string {
+	switch flag {
+	case '+':
+		return refUpdateForced
+	case '-':
+		return refUpdateDeleted
+	case 't':
+		return refUpdateTagUpdate
+	case '!':
+		return refUpdateRejected
+	case '=':
+		return refUpdateUpToDate
+	case '*':
+		switch summary {
+		case "[new branch]":
+			return refUpdateNewBranch
+		case "[new tag]":
+			return refUpdateNewTag
+		}
+		return refUpdateNewRef
+	}
+	return refUpdateFastForward
+}
+
+func (this GitRefUpdate) String() string {
+	summary := "[" + strings.ReplaceAll(this.Flag, "-", " ") + "]"
+	if this.OldCommit != "" {
+		summary = this.OldCommit + ".." + this.NewCommit
+	}
+	remoteRef := this.RemoteRef
+	if remoteRef == "" {
+		remoteRef = "(none)"
+	}
+	return fmt.Sprintf("%-19s %s -> %s (%s)", summary, remoteRef, this.Ref, this.Flag)
+}
+
+// FetchLog renders the ref updates of the fetch, in the spirit of the
+// (less consistent) output of 'git fetch' itself.
+func (this *GitReport) FetchLog() string {
+	if len(this.Fetched) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("From " + this.RemoteOutput + "\n")
+	for _, update := range this.Fetched {
+		b.WriteString("  " + update.String() + "\n")
+	}
+	return b.String()
+}
+
+// ForcePushed reports whether the fetch rewrote the history of the default branch.
+func (this *GitReport) ForcePushed() bool {
+	for _, update := range this.Fetched {
+		if update.Flag == refUpdateForced && update.Ref == this.RemoteBranch() {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	fetchErrorAuth     = "auth"
+	fetchErrorNetwork  = "network"
+	fetchErrorNotFound = "not-found"
+	fetchErrorLock     = "lock"
+	fetchErrorTimeout  = "timeout"
+	fetchErrorOther    = "other"
+)
+
+var fetchRetryDelay = time.Second // doubled after each retry
+
+// fetchErrorPatterns are matched (in order) against each lower-cased line of
+// the output of a failed 'git fetch' to classify the failure, where '*' in a
+// pattern stands for any text.
+var fetchErrorPatterns = []struct {
+	class    string
+	patterns []string
+}{
+	{fetchErrorLock, []string{
+		".lock': file exists",
+		"cannot lock ref",
+		"unable to create '*.lock'",
+	}},
+	{fetchErrorNotFound, []string{
+		"repository not found",
+		"does not appear to be a git repository",
+		"returned error: 404",
+		"couldn't find remote ref",
+	}},
+	{fetchErrorAuth, []string{
+		"permission denied",
+		"authentication failed",
+		"could not read username",
+		"could not read password",
+		"terminal prompts disabled",
+		"host key verification failed",
+		"returned error: 401",
+		"returned error: 403",
+	}},
+	{fetchErrorNetwork, []string{
+		"could not resolve host",
+		"temporary failure in name resolution",
+		"connection reset",
+		"connection refused",
+		"connection timed out",
+		"operation timed out",
+		"network is unreachable",
+		"no route to host",
+		"failed to connect",
+		"the remote end hung up unexpectedly",
+		"early eof",
+		"rpc failed",
+		"returned error: 5",
+	}},
+}
+
+// classifyFetchError names the kind of failure of 'git fetch' from its output.
+func classifyFetchError(out string) string {
+	lines := strings.Split(strings.ToLower(out), "\n")
+	for _, class := range fetchErrorPatterns {
+		for _, pattern := range class.patterns {
+			for _, line := range lines {
+				if matchFetchError(line, pattern) {
+					return class.class
+				}
+			}
+		}
+	}
+	return fetchErrorOther
+}
+
+// matchFetchError reports whether line contains the parts of pattern (which
+// are separated by '*') in order.
+func matchFetchError(line, pattern string) bool {
+	for _, part := range strings.Split(pattern, "*") {
+		i := strings.Index(line, part)
+		if i < 0 {
+			return false
+		}
+		line = line[i+len(part):]
+	}
+	return true
+}
+
+// transientFetchError reports whether a fetch which failed for the given
+// reason is likely to succeed if tried again.
+func transientFetchError(class string) bool {
+	return class == fetchErrorNetwork || class == fetchErrorLock
+}
+
+func (this *GitReport) TransientFetchError() bool {
+	return this.FetchError != "" && transientFetchError(this.FetchErrorClass)
+}
+
+// FetchErrorMessage is the error of the (last) fetch along with the reason
+// it failed and the number of attempts made.
+func (this *GitReport) FetchErrorMessage() string {
+	return fmt.Sprintf("%s(%s error; attempts: %d)\n", this.FetchError, this.FetchErrorClass, this.FetchAttempts)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGitFetch(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		out  string
+		want []GitRefUpdate
+	}{
+		{
+			name: "nothing fetched",
+			out:  "",
+			want: nil,
+		},
+		{
+			name: "fast-forward",
+			out: "From github.com:smarty/gitreview\n" +
+				"   7761a97..1bbecb6  master     -> origin/master\n",
+			want: []GitRefUpdate{
+				{Flag: refUpdateFastForward, OldCommit: "7761a97", NewCommit: "1bbecb6", Ref: "origin/master", RemoteRef: "master"},
+			},
+		},
+		{
+			name: "forced",
+			out: "From github.com:smarty/gitreview\n" +
+				" + 1bbecb6...a2f7d81 feature    -> origin/feature  (forced update)\n",
+			want: []GitRefUpdate{
+				{Flag: refUpdateForced, OldCommit: "1bbecb6", NewCommit: "a2f7d81", Ref: "origin/feature", RemoteRef: "feature"},
+			},
+		},
+		{
+			name: "deleted",
+			out: "From github.com:smarty/gitreview\n" +
+				" - [deleted]         (none)     -> origin/old-feature\n",
+			want: []GitRefUpdate{
+				{Flag: refUpdateDeleted, Ref: "origin/old-feature"},
+			},
+		},
+		{
+			name: "new tag and branch",
+			out: "From github.com:smarty/gitreview\n" +
+				" * [new tag]         v1.2.3     -> v1.2.3\n" +
+				" * [new branch]      topic      -> origin/topic\n",
+			want: []GitRefUpdate{
+				{Flag: refUpdateNewTag, Ref: "v1.2.3", RemoteRef: "v1.2.3"},
+				{Flag: refUpdateNewBranch, Ref: "origin/topic", RemoteRef: "topic"},
+			},
+		},
+		{
+			name: "tag update and rejected (with carriage returns)",
+			out: " t [tag update]      v1.0       -> v1.0\r\n" +
+				" ! [rejected]        v2.0       -> v2.0  (would clobber existing tag)\r\n",
+			want: []GitRefUpdate{
+				{Flag: refUpdateTagUpdate, Ref: "v1.0", RemoteRef: "v1.0"},
+				{Flag: refUpdateRejected, Ref: "v2.0", RemoteRef: "v2.0"},
+			},
+		},
+		{
+			name: "other output is ignored",
+			out: "remote: Enumerating objects: 5, done.\n" +
+				"fatal: could not read from remote repository -> nowhere\n" +
+				" * [new branch\n",
+			want: nil,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseGitFetch(test.out); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseGitFetch() =\n%#v\nwant\n%#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestClassifyFetchError(t *testing.T) {
+	for _, test := range []struct {
+		out  string
+		want string
+	}{
+		{"error: cannot lock ref 'refs/remotes/origin/master': is at 1bbecb6 but expected 7761a97", fetchErrorLock},
+		{"fatal: Unable to create '/src/app/.git/shallow.lock': File exists.", fetchErrorLock},
+		{"error: unable to create '/src/app/.git/refs/remotes/origin/x.lock'", fetchErrorLock},
+		{"fatal: unable to create temporary file: No space left on device", fetchErrorOther},
+		{"fatal: unable to create thread: Resource temporarily unavailable", fetchErrorOther},
+		{"ERROR: Repository not found.\nfatal: Could not read from remote repository.", fetchErrorNotFound},
+		{"git@github.com: Permission denied (publickey).", fetchErrorAuth},
+		{"ssh: Could not resolve hostname github.com: Temporary failure in name resolution", fetchErrorNetwork},
+		{"fatal: the remote end hung up unexpectedly", fetchErrorNetwork},
+		{"fatal: something else entirely", fetchErrorOther},
+	} {
+		if got := classifyFetchError(test.out); got != test.want {
+			t.Errorf("classifyFetchError(%q) = %s, want %s", test.out, got, test.want)
+		}
+	}
+}
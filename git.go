@@ -1,124 +1,528 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 )
 
 var (
 	gitRemoteCommand         = "git remote -v"                            // ie. [origin	git@github.com:smarty/gitreview.git (fetch)]
-	gitStatusCommand         = "git status --porcelain -uall"             // parse-able output, including untracked
+	gitWorktreeCommand       = "git worktree list --porcelain"            // blocks of 'worktree <path>', 'HEAD <sha>', 'branch <ref>'
+	gitSubmoduleCommand      = "git submodule --quiet foreach pwd"        // absolute path of each initialized submodule
 	gitFetchCommand          = "git fetch"                                // --dry-run"  // for debugging
 	gitFetchPendingReview    = "->"                                       // ie. [7761a97..1bbecb6  master     -> origin/master]
-	gitRevListCommand        = "git rev-list --left-right %s...origin/%s" // 1 line per commit w/ prefix '<' (ahead) or '>' (behind)
+	gitRevListCommand        = "git rev-list --left-right %s...%s/%s"     // 1 line per commit w/ prefix '<' (ahead) or '>' (behind)
+	gitRevParseCommand       = "git rev-parse --verify --quiet %s"        // the commit id of a ref (or nothing)
+	gitUnreviewedCommand     = "git rev-list %s..%s"                      // 1 line per commit not yet reviewed
+	gitUpdateMarkerCommand   = "git update-ref %s %s"                     // records the last reviewed commit
+	gitReviewMarker          = "refs/review/last"                         // the last reviewed commit of <remote>/<default-branch>
+	gitUpstreamCommand       = "git rev-parse --abbrev-ref %s@{upstream}" // ie. [origin/master]
 	gitErrorTemplate         = "[ERROR] Could not execute [%s]: %v" + "\n"
+	gitCommitSummaryCommand  = "git show --no-patch --format=%h%x09%an%x09%ar%x09%s" // + commits; 1 tab-separated line per commit
 	gitOmitCommand           = "git config --get review.omit"
 	gitSkipCommand           = "git config --get review.skip"
+	gitJournalCommand        = "git config --get --type=bool review.journal"
 	gitDefaultBranchCommand  = "git config --get review.branch"
+	gitComparisonRemote      = "git config --get review.remote"
+	gitRemoteHeadCommand     = "git symbolic-ref --quiet --short refs/remotes/%s/HEAD" // ie. [origin/main]
+	gitLsRemoteHeadCommand   = "git ls-remote --symref %s HEAD"                        // ie. [ref: refs/heads/main	HEAD]
+	gitStandardRemote        = "origin"
 	gitStandardDefaultBranch = "master"
 )
 
-func GitRevListCommand(branch string) string {
-	return fmt.Sprintf(gitRevListCommand, branch, branch)
+func GitRevListCommand(remote, branch string) string {
+	return fmt.Sprintf(gitRevListCommand, branch, remote, branch)
 }
 
 type GitReport struct {
-	RepoPath string
+	RepoPath string `json:"path"`
 
-	RemoteError  string
-	StatusError  string
-	FetchError   string
-	RevListError string
+	RemoteError  string `json:"remote_error,omitempty"`
+	StatusError  string `json:"status_error,omitempty"`
+	FetchError   string `json:"fetch_error,omitempty"`
+	RevListError string `json:"rev_list_error,omitempty"`
+	TimedOut     bool   `json:"timed_out"` // a command ran past -timeout or -deadline
 
-	RemoteOutput  string
-	StatusOutput  string
-	FetchOutput   string
-	RevListOutput string
-	OmitOutput    string
-	SkipOutput    string
+	FetchErrorClass string `json:"fetch_error_class,omitempty"` // ie. auth, network, not-found, lock, timeout, other
+	FetchAttempts   int    `json:"fetch_attempts,omitempty"`
 
-	RevListAhead  string
-	RevListBehind string
+	RemoteOutput string         `json:"remote"` // the url of the comparison remote
+	RemoteName   string         `json:"remote_name,omitempty"`
+	Remotes      []GitRemote    `json:"remotes,omitempty"`
+	Status       GitStatus      `json:"status"`
+	Stashes      int            `json:"stashes"`
+	Operation    string         `json:"operation,omitempty"` // ie. rebase, merge, cherry-pick
+	Detached     bool           `json:"detached"`
+	Bisecting    bool           `json:"bisecting"`
+	Fetched      []GitRefUpdate `json:"fetched,omitempty"`
+	Omitted      bool           `json:"omitted"`
+	Skipped      bool           `json:"skipped"`
+	Journal      string         `json:"journal,omitempty"` // the review.journal config value, if any
+
+	Branch        string   `json:"branch,omitempty"`
+	Upstream      string   `json:"upstream,omitempty"`
+	Ahead         int      `json:"ahead"`
+	Behind        int      `json:"behind"`
+	BehindCommits []string `json:"behind_commits,omitempty"`
+
+	PreviousRemoteCommit string   `json:"previous_remote_commit,omitempty"` // before the fetch
+	RemoteCommit         string   `json:"remote_commit,omitempty"`
+	ReviewedCommit       string   `json:"reviewed_commit,omitempty"`
+	Unreviewed           []string `json:"unreviewed,omitempty"`
+
+	Branches   []GitBranch    `json:"branches,omitempty"`
+	Worktrees  []*GitWorktree `json:"worktrees,omitempty"`
+	Submodules []*GitReport   `json:"submodules,omitempty"`
+
+	overrides        map[string]string // review.* settings from the config file (see Config.RepositoryOverrides)
+	remoteConfigured bool              // by review.remote (see GitRemote)
+}
+
+// GitRemote is a single remote as listed by 'git remote -v'.
+type GitRemote struct {
+	Name     string `json:"name"`
+	FetchURL string `json:"fetch_url"`
+	PushURL  string `json:"push_url,omitempty"`
+}
+
+// GitWorktree is a linked worktree of the repository at GitReport.RepoPath.
+type GitWorktree struct {
+	Path     string `json:"path"`
+	Branch   string `json:"branch,omitempty"`
+	Detached bool   `json:"detached"`
+
+	StatusError string    `json:"status_error,omitempty"`
+	Status      GitStatus `json:"status"`
+}
+
+// failed formats the error of command, noting whether the command timed out.
+func (this *GitReport) failed(command string, err error) string {
+	if errors.Is(err, errCommandTimeout) {
+		this.TimedOut = true
+	}
+	return fmt.Sprintf(gitErrorTemplate, command, err)
 }
 
-func (this *GitReport) GitRemote() {
-	out, err := execute(this.RepoPath, gitRemoteCommand)
+func (this *GitReport) GitRemote(ctx context.Context) {
+	out, err := execute(ctx, this.RepoPath, gitRemoteCommand)
 	if err != nil {
-		this.RemoteError = fmt.Sprintf(gitErrorTemplate, gitRemoteCommand, err)
+		this.RemoteError = this.failed(gitRemoteCommand, err)
 		this.RemoteOutput = this.RepoPath
 		return
 	}
-	fields := strings.Fields(out)
-	if len(fields) < 2 {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		if len(this.Remotes) == 0 || this.Remotes[len(this.Remotes)-1].Name != fields[0] {
+			this.Remotes = append(this.Remotes, GitRemote{Name: fields[0]})
+		}
+		remote := &this.Remotes[len(this.Remotes)-1]
+		if fields[2] == "(push)" {
+			remote.PushURL = fields[1]
+		} else {
+			remote.FetchURL = fields[1]
+		}
+	}
+
+	this.RemoteName = gitStandardRemote
+	configured, _ := this.gitConfig(ctx, gitComparisonRemote)
+	if configured = strings.TrimSpace(configured); configured != "" {
+		this.RemoteName, this.remoteConfigured = configured, true
+	}
+	for _, remote := range this.Remotes {
+		if remote.Name == this.RemoteName {
+			this.RemoteOutput = remote.FetchURL
+			return
+		}
+	}
+	if this.remoteConfigured {
+		this.RemoteError = fmt.Sprintf("[ERROR] The remote named by review.remote does not exist: %s\n", configured)
+		this.RemoteOutput = this.RepoPath
 		return
 	}
-	this.RemoteOutput = fields[1]
+	if len(this.Remotes) > 0 {
+		this.RemoteOutput = this.Remotes[0].FetchURL
+	}
 }
 
-func (this *GitReport) GitStatus() {
-	out, err := execute(this.RepoPath, gitStatusCommand)
-	if err != nil {
-		this.StatusError = fmt.Sprintf(gitErrorTemplate, gitStatusCommand, err)
-		return
+// upstreamRemote finds the remote of the upstream of the default branch, ie.
+// [upstream] of [upstream/master] (remote names may contain slashes).
+func (this *GitReport) upstreamRemote() (name string) {
+	for _, remote := range this.Remotes {
+		if strings.HasPrefix(this.Upstream, remote.Name+"/") && len(remote.Name) > len(name) {
+			name = remote.Name
+		}
 	}
-	if len(strings.TrimSpace(out)) > 0 {
-		this.StatusOutput = out
+	return name
+}
+
+// RemoteBranch is the remote-tracking branch the default branch is compared with.
+func (this *GitReport) RemoteBranch() string {
+	return this.RemoteName + "/" + this.Branch
+}
+
+func (this *GitReport) GitStatus(ctx context.Context, ignoredSize int64) {
+	status, err := gitStatus(ctx, this.RepoPath, ignoredSize)
+	if err != nil {
+		this.StatusError = this.failed(gitStatusCommandFor(ignoredSize), err)
 	}
+	this.Status = status
 }
 
-func (this *GitReport) GitSkipStatus() bool {
-	out, _ := execute(this.RepoPath, gitSkipCommand)
-	this.SkipOutput = out
-	return strings.Contains(out, "true")
+func (this *GitReport) GitWorktrees(ctx context.Context, ignoredSize int64) {
+	out, err := execute(ctx, this.RepoPath, gitWorktreeCommand)
+	if err != nil {
+		return // worktrees are optional (and unsupported by very old versions of git)
+	}
+	var worktree *GitWorktree
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch key {
+		case "worktree":
+			worktree = &GitWorktree{Path: value}
+			if !this.isOwnWorktree(value) && isWorktree(value) {
+				this.Worktrees = append(this.Worktrees, worktree)
+			}
+		case "branch":
+			worktree.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "detached":
+			worktree.Detached = true
+		}
+	}
+	for _, worktree := range this.Worktrees {
+		if err := worktree.GitStatus(ctx, ignoredSize); errors.Is(err, errCommandTimeout) {
+			this.TimedOut = true
+		}
+	}
 }
 
-func (this *GitReport) GitOmitStatus() bool {
-	out, _ := execute(this.RepoPath, gitOmitCommand)
-	this.OmitOutput = out
-	return strings.Contains(out, "true")
+func (this *GitReport) isOwnWorktree(path string) bool {
+	return resolvePath(path) == resolvePath(this.RepoPath)
 }
 
-func (this *GitReport) GitDefaultBranch() string {
-	out, _ := execute(this.RepoPath, gitDefaultBranchCommand)
-	branch := strings.TrimSpace(out)
-	if branch == "" {
-		return gitStandardDefaultBranch
+func (this *GitReport) GitSubmodules(ctx context.Context) (paths []string) {
+	out, err := execute(ctx, this.RepoPath, gitSubmoduleCommand)
+	if err != nil {
+		return nil
 	}
-	return branch
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
 }
 
-func (this *GitReport) GitFetch() {
-	out, err := execute(this.RepoPath, gitFetchCommand)
+func (this *GitWorktree) GitStatus(ctx context.Context, ignoredSize int64) error {
+	status, err := gitStatus(ctx, this.Path, ignoredSize)
 	if err != nil {
-		this.FetchError = fmt.Sprintf(gitErrorTemplate, gitFetchCommand, err)
+		this.StatusError = fmt.Sprintf(gitErrorTemplate, gitStatusCommandFor(ignoredSize), err)
+	}
+	this.Status = status
+	return err
+}
+
+func (this *GitWorktree) Progress() string {
+	status := " "
+	if len(this.StatusError) > 0 {
+		status = "!"
+	}
+	if this.Status.Messy() {
+		status += "M"
+	}
+	branch := this.Branch
+	if this.Detached {
+		branch = "detached HEAD"
+	}
+	return fmt.Sprintf("[%-14s] %s (worktree: %s)", status, this.Path, branch)
+}
+
+// gitConfig runs the 'git config --get <name>' command, unless the config file
+// overrides the named setting for this repository.
+func (this *GitReport) gitConfig(ctx context.Context, command string) (string, error) {
+	fields := strings.Fields(command)
+	if value, found := this.overrides[fields[len(fields)-1]]; found {
+		return value + "\n", nil
 	}
-	if strings.Contains(out, gitFetchPendingReview) {
-		this.FetchOutput = out
+	return execute(ctx, this.RepoPath, command)
+}
+
+func (this *GitReport) GitSkipStatus(ctx context.Context) bool {
+	out, _ := this.gitConfig(ctx, gitSkipCommand)
+	this.Skipped = strings.Contains(out, "true")
+	return this.Skipped
+}
+
+func (this *GitReport) GitOmitStatus(ctx context.Context) bool {
+	out, _ := this.gitConfig(ctx, gitOmitCommand)
+	this.Omitted = strings.Contains(out, "true")
+	return this.Omitted
+}
+
+func (this *GitReport) GitJournalStatus(ctx context.Context) {
+	out, err := this.gitConfig(ctx, gitJournalCommand)
+	if err == nil {
+		this.Journal = strings.TrimSpace(out)
 	}
 }
 
-func (this *GitReport) GitRevList() {
-	branch := this.GitDefaultBranch()
-	command := GitRevListCommand(branch)
-	out, err := execute(this.RepoPath, command)
+// GitDefaultBranch resolves the default branch from (in order of precedence)
+// the review.branch config, the remote's HEAD as last fetched, the remote's
+// HEAD as currently advertised by the remote, or else the standard default.
+func (this *GitReport) GitDefaultBranch(ctx context.Context) string {
+	out, _ := this.gitConfig(ctx, gitDefaultBranchCommand)
+	if branch := strings.TrimSpace(out); branch != "" {
+		return branch
+	}
+
+	out, err := execute(ctx, this.RepoPath, fmt.Sprintf(gitRemoteHeadCommand, this.RemoteName))
+	if branch := strings.TrimSpace(out); err == nil && branch != "" {
+		return strings.TrimPrefix(branch, this.RemoteName+"/")
+	}
+
+	out, err = execute(ctx, this.RepoPath, fmt.Sprintf(gitLsRemoteHeadCommand, this.RemoteName))
+	if err == nil {
+		for _, line := range strings.Split(out, "\n") {
+			ref, head, _ := strings.Cut(strings.TrimSpace(line), "\t")
+			if head == "HEAD" && strings.HasPrefix(ref, "ref: refs/heads/") {
+				return strings.TrimPrefix(ref, "ref: refs/heads/")
+			}
+		}
+	}
+
+	return gitStandardDefaultBranch
+}
+
+// GitFetch fetches from the comparison remote, retrying (with a growing delay
+// in between) at most retries times while the fetch fails for a transient
+// reason (see classifyFetchError). The commit of <remote>/<default-branch>
+// is noted beforehand (see GitReviewMarker).
+func (this *GitReport) GitFetch(ctx context.Context, prune bool, retries int) {
+	this.PreviousRemoteCommit = this.revParse(ctx, this.RemoteBranch())
+	command := gitFetchCommand + " " + this.RemoteName
+	if prune {
+		command = gitFetchCommand + " --prune " + this.RemoteName
+	}
+	delay := fetchRetryDelay
+	for {
+		this.FetchAttempts++
+		out, err := execute(ctx, this.RepoPath, command)
+		this.Fetched = parseGitFetch(out)
+		if err == nil {
+			this.FetchError, this.FetchErrorClass = "", ""
+			return
+		}
+		this.FetchError = this.failed(command, err)
+		this.FetchErrorClass = classifyFetchError(out)
+		if errors.Is(err, errCommandTimeout) {
+			this.FetchErrorClass = fetchErrorTimeout
+		}
+		if this.FetchAttempts > retries || !transientFetchError(this.FetchErrorClass) {
+			return
+		}
+		log.Printf("Retrying fetch of %s in %v (%s error)...", this.RepoPath, delay, this.FetchErrorClass)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// GitBranch resolves the default branch (see GitDefaultBranch) and its
+// upstream. Unless review.remote is set, the remote of the upstream becomes
+// the comparison remote.
+func (this *GitReport) GitBranch(ctx context.Context) {
+	this.Branch = this.GitDefaultBranch(ctx)
+	this.Upstream = this.GitUpstream(ctx, this.Branch)
+	if remote := this.upstreamRemote(); !this.remoteConfigured && remote != "" && remote != this.RemoteName {
+		this.RemoteName = remote
+		for _, candidate := range this.Remotes {
+			if candidate.Name == remote {
+				this.RemoteOutput = candidate.FetchURL
+			}
+		}
+	}
+}
+
+func (this *GitReport) GitRevList(ctx context.Context) {
+	command := GitRevListCommand(this.RemoteName, this.Branch)
+	out, err := execute(ctx, this.RepoPath, command)
 	if err != nil {
-		this.RevListError = fmt.Sprintf(gitErrorTemplate, command, err)
+		this.RevListError = this.failed(command, err)
 	}
-	behind, ahead := 0, 0
 	for _, line := range strings.Split(out, "\n") {
 		if strings.HasPrefix(line, ">") {
-			this.RevListOutput += "  " + line + "\n"
-			behind++
+			this.BehindCommits = append(this.BehindCommits, strings.TrimPrefix(line, ">"))
+			this.Behind++
 		} else if strings.HasPrefix(line, "<") {
-			ahead++
+			this.Ahead++
+		}
+	}
+}
+
+// GitUpstream resolves the configured upstream of the branch (if any).
+func (this *GitReport) GitUpstream(ctx context.Context, branch string) string {
+	out, err := execute(ctx, this.RepoPath, fmt.Sprintf(gitUpstreamCommand, branch))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// GitReviewMarker finds the commits of <remote>/<default-branch> that arrived
+// since the last review (however they were fetched), according to the
+// marker ref which is advanced after each review. A repository without the
+// marker gets one at the commit preceding the fetch, so that the commits just
+// fetched remain to be reviewed.
+func (this *GitReport) GitReviewMarker(ctx context.Context) {
+	this.RemoteCommit = this.revParse(ctx, this.RemoteBranch())
+	this.ReviewedCommit = this.revParse(ctx, gitReviewMarker)
+	if this.ReviewedCommit == "" && this.PreviousRemoteCommit != "" {
+		if err := this.updateReviewMarker(ctx, this.PreviousRemoteCommit); err == nil {
+			this.ReviewedCommit = this.PreviousRemoteCommit
+		}
+	}
+	if this.RemoteCommit == "" || this.ReviewedCommit == "" || this.RemoteCommit == this.ReviewedCommit {
+		return
+	}
+	command := fmt.Sprintf(gitUnreviewedCommand, this.ReviewedCommit, this.RemoteCommit)
+	out, err := execute(ctx, this.RepoPath, command)
+	if err != nil {
+		this.RevListError += this.failed(command, err)
+		return
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			this.Unreviewed = append(this.Unreviewed, line)
 		}
 	}
-	if ahead > 0 {
-		this.RevListAhead = fmt.Sprintf("The %s branch is %d commits ahead of origin/%s.\n", branch, ahead, branch)
+}
+
+func (this *GitReport) revParse(ctx context.Context, ref string) string {
+	out, err := execute(ctx, this.RepoPath, fmt.Sprintf(gitRevParseCommand, ref))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// AdvanceReviewMarker records the remote commit observed during analysis as reviewed.
+func (this *GitReport) AdvanceReviewMarker() error {
+	return this.updateReviewMarker(context.Background(), this.RemoteCommit)
+}
+
+func (this *GitReport) updateReviewMarker(ctx context.Context, commit string) error {
+	command := fmt.Sprintf(gitUpdateMarkerCommand, gitReviewMarker, commit)
+	out, err := execute(ctx, this.RepoPath, command)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// ReviewCommits lists the commits to review, which are the unreviewed commits
+// when a review marker is available and otherwise the commits the default
+// branch is behind.
+func (this *GitReport) ReviewCommits() []string {
+	if this.ReviewedCommit == "" {
+		return this.BehindCommits
+	}
+	return this.Unreviewed
+}
+
+// ReviewRange is the range of new commits on the remote-tracking branch: since
+// the last reviewed commit, or else since the commit the fetch updated the
+// branch from, or else since the local default branch (when behind). The
+// range is not ok when none of those apply.
+func (this *GitReport) ReviewRange() (base, head string, ok bool) {
+	head = this.RemoteBranch()
+	if this.ReviewedCommit != "" && len(this.Unreviewed) > 0 {
+		return this.ReviewedCommit, head, true
+	}
+	for _, update := range this.Fetched {
+		if update.Ref == head && update.OldCommit != "" {
+			return update.OldCommit, head, true
+		}
 	}
-	if behind > 0 {
-		this.RevListBehind = fmt.Sprintf("The %s branch is %d commits behind origin/%s.\n", branch, behind, branch)
+	if this.Behind > 0 {
+		return this.Branch, head, true
 	}
+	return "", head, false
+}
+
+// GitCommitSummary is the abbreviated id, author, age and subject of a commit.
+type GitCommitSummary struct {
+	ID      string
+	Author  string
+	Age     string
+	Subject string
+}
+
+func (this *GitReport) GitCommitSummaries(commits []string) (summaries []GitCommitSummary, err error) {
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	command := gitCommitSummaryCommand + " " + strings.Join(commits, " ")
+	out, err := execute(context.Background(), this.RepoPath, command)
+	if err != nil {
+		return nil, fmt.Errorf(gitErrorTemplate, command, err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		summaries = append(summaries, GitCommitSummary{ID: fields[0], Author: fields[1], Age: fields[2], Subject: fields[3]})
+	}
+	return summaries, nil
+}
+
+// ReviewLog lists the fetched ref updates along with the commits to review.
+func (this *GitReport) ReviewLog() string {
+	if this.ReviewedCommit == "" {
+		return this.FetchLog() + this.RevListOutput()
+	}
+	var b strings.Builder
+	b.WriteString(this.FetchLog())
+	if len(this.Fetched) == 0 && len(this.Unreviewed) > 0 {
+		b.WriteString("From " + this.RemoteOutput + "\n")
+	}
+	for _, commit := range this.Unreviewed {
+		b.WriteString("  >" + commit + "\n")
+	}
+	return b.String()
+}
+
+// RevListOutput lists the commits the branch is behind, in the format
+// of the 'git rev-list --left-right' command that found them.
+func (this *GitReport) RevListOutput() string {
+	var b strings.Builder
+	for _, commit := range this.BehindCommits {
+		b.WriteString("  >" + commit + "\n")
+	}
+	return b.String()
+}
+
+func (this *GitReport) RevListAhead() string {
+	if this.Ahead == 0 {
+		return ""
+	}
+	return fmt.Sprintf("The %s branch is %d commits ahead of %s.\n", this.Branch, this.Ahead, this.RemoteBranch())
+}
+
+func (this *GitReport) RevListBehind() string {
+	if this.Behind == 0 {
+		return ""
+	}
+	return fmt.Sprintf("The %s branch is %d commits behind %s.\n", this.Branch, this.Behind, this.RemoteBranch())
 }
 
 func (this *GitReport) Progress() string {
@@ -128,35 +532,79 @@ func (this *GitReport) Progress() string {
 	} else {
 		status += " "
 	}
-	if len(this.StatusOutput) > 0 {
+	if this.Status.Messy() {
 		status += "M"
 	} else {
 		status += " "
 	}
-	if len(this.RevListAhead) > 0 {
+	if this.Ahead > 0 {
 		status += "A"
 	} else {
 		status += " "
 	}
-	if len(this.RevListBehind) > 0 {
+	if this.Behind > 0 {
 		status += "B"
 	} else {
 		status += " "
 	}
-	if len(this.FetchOutput) > 0 {
+	if len(this.Fetched) > 0 || len(this.Unreviewed) > 0 {
 		status += "F"
 	} else {
 		status += " "
 	}
-	if len(this.OmitOutput) > 0 {
+	if this.ForcePushed() {
+		status += "X"
+	} else {
+		status += " "
+	}
+	if this.upstreamGone() {
+		status += "G"
+	} else {
+		status += " "
+	}
+	if this.Stashes > 0 {
+		status += "Z"
+	} else {
+		status += " "
+	}
+	if this.Operation != "" {
+		status += "P"
+	} else {
+		status += " "
+	}
+	if this.Detached {
+		status += "D"
+	} else {
+		status += " "
+	}
+	if this.Bisecting {
+		status += "I"
+	} else {
+		status += " "
+	}
+	if this.TimedOut {
+		status += "T"
+	} else {
+		status += " "
+	}
+	if this.Omitted {
 		status += "O"
 	} else {
 		status += " "
 	}
-	if len(this.SkipOutput) > 0 {
+	if this.Skipped {
 		status += "S"
 	} else {
 		status += " "
 	}
-	return fmt.Sprintf("[%-7s] %s", status, this.RepoPath)
+	var branches []string
+	for _, branch := range this.Branches {
+		if branch.Ahead > 0 || branch.Behind > 0 || branch.Gone {
+			branches = append(branches, branch.Progress())
+		}
+	}
+	if len(branches) > 0 {
+		return fmt.Sprintf("[%-14s] %s (%s)", status, this.RepoPath, strings.Join(branches, ", "))
+	}
+	return fmt.Sprintf("[%-14s] %s", status, this.RepoPath)
 }
@@ -2,14 +2,18 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
-func collectGitRepositories(gitRoots []string) (gits []string) {
+func collectGitRepositories(gitRoots []string, depth int, prune []string, nested bool) (gits []string) {
 	for _, root := range gitRoots {
 		if root == "." {
 			continue
@@ -17,26 +21,56 @@ func collectGitRepositories(gitRoots []string) (gits []string) {
 		if strings.TrimSpace(root) == "" {
 			continue
 		}
-		listing, err := os.ReadDir(root)
-		if err != nil {
-			log.Println("Couldn't resolve path (skipping):", err)
+		gits = append(gits, walkGitRepositories(root, depth, prune, nested)...)
+	}
+
+	return gits
+}
+
+// walkGitRepositories scans dir for git repositories, descending at most depth
+// levels (depth < 1 means no limit). Below the first level, directories
+// matching any prune pattern are never entered (so a repository named vendor
+// directly within dir is still found) and, unless nested is set, neither are
+// git repositories.
+func walkGitRepositories(dir string, depth int, prune []string, nested bool) (gits []string) {
+	return walkGitDirectory(dir, depth, prune, nested, nil)
+}
+
+func walkGitDirectory(dir string, depth int, prune []string, nested bool, pruned []string) (gits []string) {
+	listing, err := os.ReadDir(dir)
+	if err != nil {
+		log.Println("Couldn't resolve path (skipping):", err)
+		return nil
+	}
+	for _, dirItem := range listing {
+		if dirItem.Name() == ".git" || isPruned(dirItem.Name(), pruned) {
 			continue
 		}
-		for _, dirItem := range listing {
-			path := filepath.Join(root, dirItem.Name())
-			item, err := dirItem.Info()
-			if err != nil {
-				continue
-			}
-			if isGitRepository(path, item) {
-				gits = append(gits, path)
-			}
+		path := filepath.Join(dir, dirItem.Name())
+		item, err := dirItem.Info()
+		if err != nil || !item.IsDir() {
+			continue
+		}
+		found := isGitRepository(path, item)
+		if found {
+			gits = append(gits, path)
+		}
+		if depth != 1 && (!found || nested) {
+			gits = append(gits, walkGitDirectory(path, depth-1, prune, nested, prune)...)
 		}
 	}
-
 	return gits
 }
 
+func isPruned(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
 func filterGitRepositories(paths []string) (gits []string) {
 	for _, path := range paths {
 		stat, err := os.Stat(path)
@@ -51,6 +85,93 @@ func filterGitRepositories(paths []string) (gits []string) {
 	return gits
 }
 
+// dedupeGitRepositories collapses paths that are linked worktrees of the same
+// repository (they share a common git directory) into a single path, preferring
+// the main worktree whenever it was among the paths provided.
+func dedupeGitRepositories(paths []string) (gits []string) {
+	index := make(map[string]int)
+	for _, path := range paths {
+		path, _ = filepath.Abs(path)
+		common := gitCommonDir(path)
+		if common == "" {
+			common = path
+		}
+		i, found := index[common]
+		if !found {
+			index[common] = len(gits)
+			gits = append(gits, path)
+			continue
+		}
+		if isMainWorktree(path) {
+			gits[i] = path
+		}
+	}
+	return gits
+}
+
+// gitCommonDir resolves the git directory holding the object store for the
+// repository checked out at path. For ordinary clones that is <path>/.git, but
+// linked worktrees and submodules have a .git file pointing elsewhere.
+func gitCommonDir(path string) string {
+	dotGit := filepath.Join(path, ".git")
+	stat, err := os.Stat(dotGit)
+	if err != nil {
+		return ""
+	}
+	if stat.IsDir() {
+		return resolvePath(dotGit)
+	}
+
+	gitDir := readGitFile(dotGit, "gitdir:")
+	if gitDir == "" {
+		return ""
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(path, gitDir)
+	}
+
+	commonDir := readGitFile(filepath.Join(gitDir, "commondir"), "")
+	if commonDir == "" {
+		return resolvePath(gitDir) // submodules have no commondir file
+	}
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return resolvePath(commonDir)
+}
+
+func isMainWorktree(path string) bool {
+	stat, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil && stat.IsDir()
+}
+
+func readGitFile(path, prefix string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	line := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(line, prefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+}
+
+// isWorktree excludes worktree entries that have been deleted (but not yet
+// pruned) as well as the git directories listed for submodules.
+func isWorktree(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && isGitRepository(path, stat)
+}
+
+func resolvePath(path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return resolved
+}
+
 func isGitRepository(path string, item os.FileInfo) bool {
 	if !item.IsDir() {
 		return false
@@ -64,17 +185,41 @@ func isGitRepository(path string, item os.FileInfo) bool {
 	return true
 }
 
-func execute(dir, command string) (string, error) {
+// commandTimeout limits the duration of each command (see -timeout).
+var commandTimeout time.Duration
+
+var errCommandTimeout = errors.New("timed out")
+
+// execute runs command in dir until it completes, ctx is done or the command
+// runs longer than commandTimeout (if positive). A command cut short is
+// interrupted (so that git removes its lock files) and only killed when it
+// hasn't exited a few seconds later.
+func execute(ctx context.Context, dir, command string) (string, error) {
+	if commandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+	}
 	args := strings.Fields(command)
-	cmd := exec.Command(args[0], args[1:]...)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 	cmd.Dir = dir
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 5 * time.Second // then kill, and don't wait on the pipes of any orphaned (grand)children
 	out, err := cmd.CombinedOutput()
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %v", errCommandTimeout, err)
+	}
 	return string(out), err
 }
 
+var stdin = bufio.NewScanner(os.Stdin)
+
 func prompt(message string) string {
 	log.Println(message)
-	s := bufio.NewScanner(os.Stdin)
-	s.Scan()
-	return s.Text()
+	return readLine()
+}
+
+func readLine() string {
+	stdin.Scan()
+	return strings.TrimSpace(stdin.Text())
 }
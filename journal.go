@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const defaultJournalRules = "text:smarty"
+
+// JournalRule includes (or, when prefixed with '!', excludes) repositories
+// from the code review log entry according to their remote url:
+//
+//	host:<host>    the host of the remote url (ie. github.com)
+//	owner:<owner>  the first element of the remote path (ie. smarty)
+//	url:<regexp>   a regular expression matching the remote url
+//	text:<text>    text contained anywhere in the remote url
+type JournalRule struct {
+	Exclude bool
+	Kind    string
+	Value   string
+	pattern *regexp.Regexp
+}
+
+func ParseJournalRules(value string) (rules []JournalRule, err error) {
+	for _, item := range splitList(value, ",") {
+		rule := JournalRule{}
+		if strings.HasPrefix(item, "!") {
+			rule.Exclude, item = true, item[1:]
+		}
+		kind, value, found := strings.Cut(item, ":")
+		if !found || value == "" {
+			return nil, fmt.Errorf("journal rule must be formatted as <kind>:<value>: %s", item)
+		}
+		rule.Kind, rule.Value = kind, value
+		switch kind {
+		case "host", "owner", "text":
+		case "url":
+			if rule.pattern, err = regexp.Compile(value); err != nil {
+				return nil, fmt.Errorf("journal rule has invalid regular expression: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("journal rule has unrecognized kind (not host, owner, url or text): %s", kind)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (this JournalRule) Matches(remote string) bool {
+	switch this.Kind {
+	case "host":
+		return strings.EqualFold(ParseGitRemoteURL(remote).Host, this.Value)
+	case "owner":
+		return strings.EqualFold(ParseGitRemoteURL(remote).Owner(), this.Value)
+	case "url":
+		return this.pattern.MatchString(remote)
+	default:
+		return strings.Contains(remote, this.Value)
+	}
+}
+
+// journalRulesMatch reports whether the remote matches at least one include
+// rule (if there are any) and none of the exclude rules.
+func journalRulesMatch(rules []JournalRule, remote string) bool {
+	included, includes := false, 0
+	for _, rule := range rules {
+		if !rule.Matches(remote) {
+			if !rule.Exclude {
+				includes++
+			}
+			continue
+		}
+		if rule.Exclude {
+			return false
+		}
+		included = true
+	}
+	return included || includes == 0
+}
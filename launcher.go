@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// guiPresets are the -gui templates of common tools, by name.
+var guiPresets = map[string]GUILauncher{
+	"smerge":  {Template: "smerge {path}"},
+	"gitk":    {Template: "gitk {range}", Fallback: "gitk --all"},
+	"tig":     {Template: "tig -C {path} {range}", Fallback: "tig -C {path}"},
+	"lazygit": {Template: "lazygit -p {path}"},
+	"code":    {Template: "code --new-window {path}"},
+	"github":  {Template: "github {path}"},
+}
+
+// rangePlaceholders can only be replaced when the repository has a range of
+// new commits (see GitReport.ReviewRange) and, for {first}, commits to review
+// (see GitReport.ReviewCommits).
+var rangePlaceholders = []string{"{base}", "{range}", "{first}"}
+
+// GUILauncher opens a repository with an external program according to a
+// template (the -gui flag) containing any of these placeholders:
+//
+//	{path}   the path of the repository
+//	{branch} the default branch, ie. master
+//	{remote} the remote-tracking branch, ie. origin/master
+//	{base}   the commit preceding the new commits (see GitReport.ReviewRange)
+//	{head}   same as {remote}
+//	{range}  {base}..{head}
+//	{first}  the first (oldest) of the new commits
+//
+// Repositories without new commits are opened with the fallback template
+// instead when the template contains {base}, {range} or {first} (as are those
+// whose new commits are only known by their range, for {first}).
+//
+// The template is split into arguments (on whitespace) before placeholders
+// are replaced, so paths with spaces are passed intact. The program runs
+// within the repository.
+type GUILauncher struct {
+	Template string
+	Fallback string
+}
+
+// NewGUILauncher interprets the -gui flag: a preset, or else a template
+// optionally followed by '|' and the fallback template, ie.
+// 'tig {range}|tig'. Without a fallback, the program is passed the path.
+func NewGUILauncher(gui string) GUILauncher {
+	if preset, found := guiPresets[gui]; found {
+		return preset
+	}
+	template, fallback, _ := strings.Cut(gui, "|")
+	if !strings.Contains(template, "{") {
+		template += " {path}" // ie. -gui gitx
+	}
+	if fields := strings.Fields(template); fallback == "" && len(fields) > 0 {
+		fallback = fields[0] + " {path}"
+	}
+	return GUILauncher{Template: strings.TrimSpace(template), Fallback: strings.TrimSpace(fallback)}
+}
+
+// Command prepares the program which opens the repository at path (report
+// is nil for paths such as worktrees which weren't analyzed on their own).
+func (this GUILauncher) Command(path string, report *GitReport) *exec.Cmd {
+	values, unavailable := placeholders(path, report)
+	template := this.Template
+	if this.Fallback != "" && containsAny(template, unavailable) {
+		template = this.Fallback
+	}
+	replacer := strings.NewReplacer(values...)
+	args := strings.Fields(template)
+	for i, arg := range args {
+		args[i] = replacer.Replace(arg)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = path
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr // for terminal programs such as tig
+	return cmd
+}
+
+// placeholders lists the placeholders (see GUILauncher) each followed by its
+// value, along with the placeholders which can't be replaced.
+func placeholders(path string, report *GitReport) (values, unavailable []string) {
+	var branch, remote, base, first string
+	ranged := false
+	if report != nil {
+		branch, remote = report.Branch, report.RemoteBranch()
+		base, _, ranged = report.ReviewRange()
+		if commits := report.ReviewCommits(); len(commits) > 0 {
+			first = commits[len(commits)-1] // rev-list lists the newest first
+		}
+	}
+	if !ranged {
+		unavailable = rangePlaceholders
+	} else if first == "" {
+		unavailable = []string{"{first}"}
+	}
+	return []string{
+		"{path}", path,
+		"{branch}", branch,
+		"{remote}", remote,
+		"{base}", base,
+		"{head}", remote,
+		"{range}", base + ".." + remote,
+		"{first}", first,
+	}, unavailable
+}
+
+func containsAny(value string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(value, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewInBatches opens the GUI for size repositories at a time and waits for
+// each batch to close (or, for GUIs which don't block, for a keypress before
+// the next batch) allowing batches to be skipped or revisited and the review
+// to be concluded early. Only the repositories actually opened are returned.
+func (this *GitReviewer) reviewInBatches(paths []string, launcher GUILauncher, size int) (reviewed []string) {
+	opened := make(map[string]bool)
+	for start := 0; start < len(paths); {
+		end := min(start+size, len(paths))
+		log.Printf("[%s] %s", batchProgress(start, end, len(paths)), strings.Join(paths[start:end], ", "))
+		command := prompt("Press <ENTER> to open, 's' to skip, 'p' to go back to the previous batch, or 'q' to conclude the review...")
+		if command == "q" {
+			break
+		}
+		if command == "s" {
+			start = end
+			continue
+		}
+		if command == "p" {
+			start = max(start-size, 0)
+			continue
+		}
+
+		var running []*exec.Cmd
+		for _, path := range paths[start:end] {
+			cmd := launcher.Command(path, this.report(path))
+			log.Printf("Opening %s at %s", strings.Join(cmd.Args, " "), path)
+			if err := cmd.Start(); err != nil {
+				log.Println("Failed to open git GUI:", err)
+				continue
+			}
+			running = append(running, cmd)
+		}
+		for _, cmd := range running {
+			_ = cmd.Wait()
+		}
+		for _, path := range paths[start:end] {
+			opened[path] = true
+			this.signOff(path)
+		}
+		start = end
+	}
+
+	for _, path := range paths {
+		if opened[path] {
+			reviewed = append(reviewed, path)
+		}
+	}
+	return reviewed
+}
+
+// batchProgress is ie. "3/40" (or "3-4/40" for batches of more than one).
+func batchProgress(start, end, total int) string {
+	if end-start == 1 {
+		return fmt.Sprintf("%d/%d", end, total)
+	}
+	return fmt.Sprintf("%d-%d/%d", start+1, end, total)
+}
@@ -1,11 +1,32 @@
 package main
 
+import "os"
+
 var Version = "dev"
 
 func main() {
 	config := ReadConfig(Version)
 	reviewer := NewGitReviewer(config)
-	reviewer.GitAnalyzeAll()
-	reviewer.ReviewAll()
-	reviewer.PrintCodeReviewLogEntry()
+	switch config.Command {
+	case commandReview, commandJournal:
+		reviewer.LoadReport(config.SavedReportPath)
+	default:
+		reviewer.GitAnalyzeAll()
+		reviewer.WriteReport()
+	}
+	if config.Batch {
+		os.Exit(reviewer.ReviewBatch())
+	}
+	switch config.Command {
+	case commandAnalyze:
+		reviewer.printSummary(reviewer.reviewable())
+		return
+	case commandJournal:
+		reviewer.ReviewJournal()
+	default:
+		reviewer.ReviewAll()
+	}
+	if concluded := reviewer.PrintCodeReviewLogEntry(); concluded && config.FastForward {
+		reviewer.FastForwardAll()
+	}
 }
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+var (
+	gitPruneCommand          = "git remote prune %s"                               // deletes stale remote-tracking branches
+	gitPruneDryRunCommand    = "git remote prune --dry-run %s"                     // ie. [ * [would prune] origin/feature]
+	gitMergedBranchesCommand = "git branch --merged %s --format=%%(refname:short)" // 1 line per branch
+	gitDeleteBranchCommand   = "git branch -d %s"
+	gitGCCommand             = "git gc --auto"
+	gitSetConfigCommand      = "git config %s %s"
+)
+
+// maintenanceAction plans the commands which carry out some chore in a
+// repository. The plan (a dry run, along with any details of what the commands
+// would do) is previewed before anything is run.
+type maintenanceAction struct {
+	name   string
+	prompt string // when not blank, asks for the value passed to plan
+	plan   func(report *GitReport, value string) (commands, details []string, err error)
+}
+
+var maintenanceActions = []maintenanceAction{
+	{name: "Prune stale remote-tracking branches", plan: planPrune},
+	{name: "Delete local branches merged into the default branch", plan: planDeleteMerged},
+	{name: "Run 'git gc --auto'", plan: planGC},
+	{name: "Skip in future reviews (review.skip)", plan: planSetConfig("review.skip", "true")},
+	{name: "Omit from the code review log (review.omit)", plan: planSetConfig("review.omit", "true")},
+	{name: "Set the branch to review (review.branch)", prompt: "Enter the name of the branch to review:", plan: planSetBranch},
+}
+
+func planPrune(report *GitReport, _ string) (commands, details []string, err error) {
+	if report.RemoteName == "" {
+		return nil, nil, nil // skipped
+	}
+	command := fmt.Sprintf(gitPruneDryRunCommand, report.RemoteName)
+	out, err := execute(context.Background(), report.RepoPath, command)
+	if err != nil {
+		return nil, nil, fmt.Errorf(gitErrorTemplate+"%s", command, err, strings.TrimSpace(out))
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "[would prune]") {
+			details = append(details, strings.TrimSpace(line))
+		}
+	}
+	if len(details) == 0 {
+		return nil, nil, nil
+	}
+	return []string{fmt.Sprintf(gitPruneCommand, report.RemoteName)}, details, nil
+}
+
+// planDeleteMerged leaves out the default branch and any branch checked out
+// (which git refuses to delete), including in linked worktrees.
+func planDeleteMerged(report *GitReport, _ string) (commands, details []string, err error) {
+	if report.Branch == "" {
+		return nil, nil, nil // skipped
+	}
+	command := fmt.Sprintf(gitMergedBranchesCommand, report.Branch)
+	out, err := execute(context.Background(), report.RepoPath, command)
+	if err != nil {
+		return nil, nil, fmt.Errorf(gitErrorTemplate+"%s", command, err, strings.TrimSpace(out))
+	}
+	checkedOut := map[string]bool{report.Branch: true, report.Status.Head: true}
+	for _, worktree := range report.Worktrees {
+		checkedOut[worktree.Branch] = true
+	}
+	for _, branch := range strings.Fields(out) {
+		if checkedOut[branch] {
+			continue
+		}
+		commands = append(commands, fmt.Sprintf(gitDeleteBranchCommand, branch))
+	}
+	return commands, nil, nil
+}
+
+func planGC(*GitReport, string) (commands, details []string, err error) {
+	return []string{gitGCCommand}, nil, nil
+}
+
+func planSetConfig(name, value string) func(*GitReport, string) ([]string, []string, error) {
+	return func(*GitReport, string) ([]string, []string, error) {
+		return []string{fmt.Sprintf(gitSetConfigCommand, name, value)}, nil, nil
+	}
+}
+
+func planSetBranch(_ *GitReport, branch string) (commands, details []string, err error) {
+	return []string{fmt.Sprintf(gitSetConfigCommand, "review.branch", branch)}, nil, nil
+}
+
+// MaintainAll offers the maintenance actions (see maintenanceActions) for
+// any of the analyzed repositories, previewing the commands of each action
+// and running them only once confirmed.
+func (this *GitReviewer) MaintainAll() {
+	reports := flattenReports(this.reports)
+	for {
+		log.Println("Maintenance actions:")
+		for i, action := range maintenanceActions {
+			log.Printf("  %d) %s", i+1, action.name)
+		}
+		n, err := strconv.Atoi(prompt("Enter the number of an action, or <ENTER> to return..."))
+		if err != nil || n < 1 || n > len(maintenanceActions) {
+			return
+		}
+		action := maintenanceActions[n-1]
+
+		var value string
+		if action.prompt != "" {
+			if value = prompt(action.prompt); len(strings.Fields(value)) != 1 {
+				log.Println("Invalid value:", value)
+				continue
+			}
+		}
+
+		for i, report := range reports {
+			log.Printf("%3d) %s", i+1, report.Progress())
+		}
+		selected, err := parseSelection(prompt("Enter the repositories (ie. '1,3-5' or 'all'):"), len(reports))
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		plans := make(map[*GitReport][]string)
+		for _, i := range selected {
+			report := reports[i]
+			commands, details, err := action.plan(report, value)
+			if err != nil {
+				log.Printf("%s: %v", report.RepoPath, err)
+				continue
+			}
+			if len(commands) > 0 {
+				plans[report] = commands
+				log.Printf("%s (dry run):\n  %s", report.RepoPath, strings.Join(append(details, commands...), "\n  "))
+			}
+		}
+		if len(plans) == 0 {
+			log.Println("Nothing to do.")
+			continue
+		}
+		if prompt(fmt.Sprintf("Run the commands above in %d repositories? (y/N)", len(plans))) != "y" {
+			continue
+		}
+		for _, i := range selected {
+			report := reports[i]
+			for _, command := range plans[report] {
+				out, err := execute(context.Background(), report.RepoPath, command)
+				if err != nil {
+					log.Printf("%s %s%s", report.RepoPath, fmt.Sprintf(gitErrorTemplate, command, err), out)
+				}
+			}
+		}
+		log.Printf("Done (%d repositories).", len(plans))
+	}
+}
+
+// parseSelection interprets a comma-separated list of numbers (starting at 1)
+// and ranges of numbers (ie. '3-5'), or 'all', as indexes into a list of n items.
+func parseSelection(input string, n int) (indexes []int, err error) {
+	if strings.TrimSpace(input) == "all" {
+		for i := 0; i < n; i++ {
+			indexes = append(indexes, i)
+		}
+		return indexes, nil
+	}
+	seen := make(map[int]bool)
+	for _, item := range splitList(input, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			last = first
+		}
+		from, err1 := strconv.Atoi(strings.TrimSpace(first))
+		to, err2 := strconv.Atoi(strings.TrimSpace(last))
+		if err1 != nil || err2 != nil || from < 1 || to > n || from > to {
+			return nil, fmt.Errorf("invalid selection: %s", item)
+		}
+		for i := from - 1; i < to; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	return indexes, nil
+}
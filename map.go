@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"sort"
+	"strings"
 )
 
 func sortUniqueKeys(maps ...map[string]string) (unique []string) {
@@ -31,6 +32,17 @@ func printMapKeys(m map[string]string, preamble string) {
 	printStrings(mapKeys(m), preamble)
 }
 
+// printMapSummaries is like printMapKeys, but follows each key with the first
+// line of its value (ie. the counts of a GitStatus).
+func printMapSummaries(m map[string]string, preamble string) {
+	keys := mapKeys(m)
+	log.Printf(preamble, len(keys))
+	for _, key := range keys {
+		summary, _, _ := strings.Cut(m[key], "\n")
+		log.Printf("  %s (%s)", key, summary)
+	}
+}
+
 func printStrings(paths []string, preamble string) {
 	log.Printf(preamble, len(paths))
 	if len(paths) == 0 {
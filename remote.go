@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// GitRemoteURL is the host and repository path of a remote url in any of the
+// forms git understands, ie:
+//
+//	git@github.com:smarty/gitreview.git
+//	ssh://git@github.com:22/smarty/gitreview.git
+//	https://github.com/smarty/gitreview.git
+//	/path/to/repository (local remotes have no host)
+type GitRemoteURL struct {
+	Host string
+	Path string // ie. smarty/gitreview
+}
+
+func ParseGitRemoteURL(remote string) GitRemoteURL {
+	remote = strings.TrimSpace(remote)
+	if strings.Contains(remote, "://") {
+		parsed, err := url.Parse(remote)
+		if err == nil {
+			return GitRemoteURL{Host: parsed.Hostname(), Path: trimRepositoryPath(parsed.Path)}
+		}
+	}
+	if host, path, found := strings.Cut(remote, ":"); found && !strings.Contains(host, "/") {
+		if _, host, found = strings.Cut(host, "@"); !found {
+			host, _, _ = strings.Cut(remote, ":")
+		}
+		return GitRemoteURL{Host: host, Path: trimRepositoryPath(path)}
+	}
+	return GitRemoteURL{Path: trimRepositoryPath(remote)}
+}
+
+func trimRepositoryPath(path string) string {
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
+
+// Owner is the first element of the path (the organization or user on most hosts).
+func (this GitRemoteURL) Owner() string {
+	owner, _, _ := strings.Cut(this.Path, "/")
+	return owner
+}
+
+// RepositoryURL is the web address of the repository (assuming the host
+// serves one at the conventional address, as GitHub, GitLab, Gitea, etc. do).
+func (this GitRemoteURL) RepositoryURL() string {
+	if this.Host == "" || this.Path == "" {
+		return ""
+	}
+	return "https://" + this.Host + "/" + this.Path
+}
+
+func (this GitRemoteURL) CommitURL(commit string) string {
+	repository := this.RepositoryURL()
+	if repository == "" {
+		return ""
+	}
+	switch {
+	case strings.Contains(this.Host, "gitlab"):
+		return repository + "/-/commit/" + commit
+	case strings.Contains(this.Host, "bitbucket"):
+		return repository + "/commits/" + commit
+	default:
+		return repository + "/commit/" + commit
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	journalFormatText     = "text"
+	journalFormatMarkdown = "markdown"
+	journalFormatHTML     = "html"
+)
+
+// JournalEntry is everything known about a single repository included in the
+// code review log entry.
+type JournalEntry struct {
+	Path     string
+	Log      string // the fetched ref updates and commits, as plain text
+	Report   *GitReport
+	Remote   GitRemoteURL
+	Commits  []GitCommitSummary
+	SignOffs []SignOff
+}
+
+func (this JournalEntry) Title() string {
+	if this.Remote.Path != "" && this.Remote.Host != "" {
+		return this.Remote.Path
+	}
+	return this.Path
+}
+
+func (this JournalEntry) SignOff(commit GitCommitSummary) string {
+	for _, signOff := range this.SignOffs {
+		if signOff.Commit.ID == commit.ID {
+			if signOff.Note == "" {
+				return signOff.Decision
+			}
+			return signOff.Decision + ": " + signOff.Note
+		}
+	}
+	return ""
+}
+
+type JournalRenderer interface {
+	Render(writer io.Writer, date time.Time, entries []JournalEntry) error
+}
+
+func NewJournalRenderer(format string) JournalRenderer {
+	switch format {
+	case journalFormatMarkdown:
+		return MarkdownJournalRenderer{}
+	case journalFormatHTML:
+		return HTMLJournalRenderer{}
+	default:
+		return TextJournalRenderer{}
+	}
+}
+
+func (this *GitReviewer) journalEntries() (entries []JournalEntry) {
+	for _, path := range mapKeys(this.journal) {
+		entry := JournalEntry{
+			Path:     path,
+			Log:      excludeSSHFingerprints(this.journal[path]),
+			Report:   this.report(path),
+			SignOffs: this.signOffs[path],
+		}
+		if entry.Report != nil {
+			entry.Remote = ParseGitRemoteURL(entry.Report.RemoteOutput)
+			commits, err := entry.Report.GitCommitSummaries(entry.Report.ReviewCommits())
+			if err != nil {
+				log.Print(err)
+			}
+			entry.Commits = commits
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// TextJournalRenderer renders the raw (plain text) log of each repository.
+type TextJournalRenderer struct{}
+
+func (TextJournalRenderer) Render(writer io.Writer, date time.Time, entries []JournalEntry) error {
+	_, err := fmt.Fprintf(writer, "\n\n##%s\n\n", date.Format("2006-01-02"))
+	for _, entry := range entries {
+		if err != nil {
+			break
+		}
+		_, err = fmt.Fprintln(writer, entry.Log+formatSignOffs(entry.SignOffs))
+	}
+	return err
+}
+
+// MarkdownJournalRenderer renders a section per repository with the fetched
+// ref updates and a table of the reviewed commits (linked to the remote).
+type MarkdownJournalRenderer struct{}
+
+func (MarkdownJournalRenderer) Render(writer io.Writer, date time.Time, entries []JournalEntry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\n## %s\n\n", date.Format("2006-01-02"))
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "### %s\n\n", entry.Title())
+		if url := entry.Remote.RepositoryURL(); url != "" {
+			fmt.Fprintf(&b, "[%s](%s) at `%s`\n\n", entry.Remote.Path, url, entry.Path)
+		} else {
+			fmt.Fprintf(&b, "`%s`\n\n", entry.Path)
+		}
+		if entry.Report != nil {
+			for _, update := range entry.Report.Fetched {
+				fmt.Fprintf(&b, "- `%s` %s\n", update.Ref, markdownRefUpdate(update))
+			}
+			if len(entry.Report.Fetched) > 0 {
+				b.WriteString("\n")
+			}
+		}
+		if len(entry.Commits) == 0 {
+			continue
+		}
+		b.WriteString("| Commit | Author | Subject | Sign-off |\n")
+		b.WriteString("|--------|--------|---------|----------|\n")
+		for _, commit := range entry.Commits {
+			id := "`" + commit.ID + "`"
+			if url := entry.Remote.CommitURL(commit.ID); url != "" {
+				id = fmt.Sprintf("[%s](%s)", id, url)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				id, markdownCell(commit.Author), markdownCell(commit.Subject), markdownCell(entry.SignOff(commit)))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+func markdownRefUpdate(update GitRefUpdate) string {
+	if update.OldCommit == "" {
+		return update.Flag
+	}
+	return fmt.Sprintf("%s (`%s..%s`)", update.Flag, update.OldCommit, update.NewCommit)
+}
+
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
+
+// HTMLJournalRenderer renders a standalone HTML document.
+type HTMLJournalRenderer struct{}
+
+func (HTMLJournalRenderer) Render(writer io.Writer, date time.Time, entries []JournalEntry) error {
+	return htmlJournalTemplate.Execute(writer, struct {
+		Date    string
+		Entries []JournalEntry
+	}{
+		Date:    date.Format("2006-01-02"),
+		Entries: entries,
+	})
+}
+
+var htmlJournalTemplate = template.Must(template.New("journal").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Code review: {{.Date}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; }
+code { font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>Code review: {{.Date}}</h1>
+{{- range $entry := .Entries}}
+<section>
+<h2>{{$entry.Title}}</h2>
+<p>{{with $entry.Remote.RepositoryURL}}<a href="{{.}}">{{$entry.Remote.Path}}</a> at {{end}}<code>{{$entry.Path}}</code></p>
+{{- with $entry.Report}}{{with .Fetched}}
+<ul>
+{{- range .}}
+<li><code>{{.Ref}}</code> {{.Flag}}{{if .OldCommit}} (<code>{{.OldCommit}}..{{.NewCommit}}</code>){{end}}</li>
+{{- end}}
+</ul>
+{{- end}}{{end}}
+{{- with $entry.Commits}}
+<table>
+<tr><th>Commit</th><th>Author</th><th>Subject</th><th>Sign-off</th></tr>
+{{- range .}}
+<tr><td>{{with $entry.Remote.CommitURL .ID}}<a href="{{.}}">{{end}}<code>{{.ID}}</code>{{if $entry.Remote.CommitURL .ID}}</a>{{end}}</td><td>{{.Author}}</td><td>{{.Subject}}</td><td>{{$entry.SignOff .}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	reportFormatText   = "text"
+	reportFormatJSON   = "json"
+	reportFormatNDJSON = "ndjson"
+)
+
+// AnalysisReport is the document written for the json report format.
+type AnalysisReport struct {
+	Timestamp    time.Time    `json:"timestamp"`
+	Repositories []*GitReport `json:"repositories"`
+}
+
+func (this *GitReviewer) WriteReport() {
+	if this.config.ReportFormat == reportFormatText {
+		return
+	}
+
+	writer := this.config.OpenReportWriter()
+	defer func() { _ = writer.Close() }()
+
+	encoder := json.NewEncoder(writer)
+	var err error
+	if this.config.ReportFormat == reportFormatNDJSON {
+		for _, report := range this.reports {
+			if err = encoder.Encode(report); err != nil {
+				break
+			}
+		}
+	} else {
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(AnalysisReport{Timestamp: time.Now(), Repositories: this.reports})
+	}
+	if err != nil {
+		log.Println("Could not write analysis report:", err)
+	}
+}
+
+// LoadReport collects the findings of a previous analysis (see the analyze
+// command) in place of analyzing the repositories again.
+func (this *GitReviewer) LoadReport(path string) {
+	report, err := ReadAnalysisReport(path)
+	if err != nil {
+		log.Fatalln("Could not read analysis report:", err)
+	}
+	log.Printf("Loaded the analysis of %d git repositories from %s (analyzed at %s).",
+		len(report.Repositories), path, report.Timestamp.Format(time.DateTime))
+	this.reports = report.Repositories
+	this.collect(this.reports)
+}
+
+// ReadAnalysisReport reads a report written in either the json or the ndjson
+// format. The timestamp of an ndjson report is the time the file was written.
+func ReadAnalysisReport(path string) (report AnalysisReport, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+	if err = json.Unmarshal(data, &report); err == nil && report.Repositories != nil {
+		return report, nil
+	}
+
+	report = AnalysisReport{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		repository := new(GitReport)
+		if err = decoder.Decode(repository); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return report, err
+		}
+		report.Repositories = append(report.Repositories, repository)
+	}
+	if info, err := os.Stat(path); err == nil {
+		report.Timestamp = info.ModTime()
+	}
+	return report, nil
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
+	"os/signal"
 	"strings"
 	"time"
 )
@@ -12,12 +14,28 @@ import (
 type GitReviewer struct {
 	config    *Config
 	repoPaths []string
+	reports   []*GitReport
+	reviewed  []string
+	signOffs  map[string][]SignOff
 
 	erred   map[string]string
+	timeout map[string]string
 	messy   map[string]string
+	changed map[string]string
 	ahead   map[string]string
 	behind  map[string]string
 	fetched map[string]string
+	forced  map[string]string
+	gone    map[string]string
+	stashed map[string]string
+	pending map[string]string
+	detach  map[string]string
+	bisects map[string]string
+
+	conflicted map[string]string
+	ignored    map[string]string
+	unfetched  map[string]string
+
 	journal map[string]string
 	omitted map[string]string
 	skipped map[string]string
@@ -26,83 +44,205 @@ type GitReviewer struct {
 func NewGitReviewer(config *Config) *GitReviewer {
 	return &GitReviewer{
 		config: config,
-		repoPaths: append(
-			collectGitRepositories(config.GitRepositoryRoots),
+		repoPaths: dedupeGitRepositories(append(
+			collectGitRepositories(
+				config.GitRepositoryRoots,
+				config.GitRepositoryDepth,
+				config.GitRepositoryPrune,
+				config.GitNestedScan,
+			),
 			filterGitRepositories(config.GitRepositoryPaths)...,
-		),
+		)),
 		erred:   make(map[string]string),
+		timeout: make(map[string]string),
 		messy:   make(map[string]string),
+		changed: make(map[string]string),
 		ahead:   make(map[string]string),
 		behind:  make(map[string]string),
 		fetched: make(map[string]string),
+		forced:  make(map[string]string),
+		gone:    make(map[string]string),
+		stashed: make(map[string]string),
+		pending: make(map[string]string),
+		detach:  make(map[string]string),
+		bisects: make(map[string]string),
+
+		conflicted: make(map[string]string),
+		ignored:    make(map[string]string),
+		unfetched:  make(map[string]string),
+
 		journal: make(map[string]string),
 		omitted: make(map[string]string),
 		skipped: make(map[string]string),
+
+		signOffs: make(map[string][]SignOff),
 	}
 }
 
+// GitAnalyzeAll analyzes every repository, abandoning any commands still
+// running after -deadline. An interrupt (Ctrl-C) cancels all commands in
+// flight and exits.
 func (this *GitReviewer) GitAnalyzeAll() {
 	log.Printf("Analyzing %d git repositories...", len(this.repoPaths))
-	log.Println("Legend: [!] = error; [M] = messy; [A] = ahead; [B] = behind; [F] = fetched; [O] = omitted; [S] = skipped;")
-	reports := NewAnalyzer(workerCount).AnalyzeAll(this.repoPaths)
-	for _, report := range reports {
+	log.Println("Legend: [!] = error; [M] = messy; [A] = ahead; [B] = behind; [F] = fetched; [X] = force-pushed; [G] = upstream gone; [Z] = stashed; [P] = operation in progress; [D] = detached HEAD; [I] = bisecting; [T] = timed out; [O] = omitted; [S] = skipped;")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if this.config.AnalysisDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, this.config.AnalysisDeadline, errAnalysisDeadline)
+		defer cancel()
+	}
+	this.reports = NewAnalyzer(this.config.Workers, this.config.HostLimits, this.config).AnalyzeAll(ctx, this.repoPaths)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		log.Println("Analysis interrupted.")
+		os.Exit(130)
+	}
+	this.collect(this.reports)
+}
+
+// collect sorts the findings of each report into the maps consulted by the review.
+func (this *GitReviewer) collect(reports []*GitReport) {
+	for _, report := range flattenReports(reports) {
+		erred := this.erred
+		if report.TimedOut {
+			erred = this.timeout
+			erred[report.RepoPath] += "" // listed even when only a worktree timed out
+		}
+		if len(report.RemoteError) > 0 {
+			erred[report.RepoPath] += report.RemoteError
+			log.Println(report.RepoPath, report.RemoteError)
+		}
 		if len(report.StatusError) > 0 {
-			this.erred[report.RepoPath] += report.StatusError
+			erred[report.RepoPath] += report.StatusError
 			log.Println(report.RepoPath, report.StatusError)
 		}
-		if len(report.FetchError) > 0 {
-			this.erred[report.RepoPath] += report.FetchError
-			log.Println(report.RepoPath, report.FetchError)
+		if report.TransientFetchError() {
+			this.unfetched[report.RepoPath] += report.FetchErrorMessage()
+			log.Println(report.RepoPath, report.FetchErrorMessage())
+		} else if len(report.FetchError) > 0 {
+			erred[report.RepoPath] += report.FetchErrorMessage()
+			log.Println(report.RepoPath, report.FetchErrorMessage())
 		}
 		if len(report.RevListError) > 0 {
-			this.erred[report.RepoPath] += report.RevListError
+			erred[report.RepoPath] += report.RevListError
 			log.Println(report.RepoPath, report.RevListError)
 		}
 
-		if len(report.StatusOutput) > 0 {
-			this.messy[report.RepoPath] += report.StatusOutput
+		this.collectStatus(report.RepoPath, report.Status)
+		for _, worktree := range report.Worktrees {
+			if len(worktree.StatusError) > 0 {
+				this.erred[worktree.Path] += worktree.StatusError
+				log.Println(worktree.Path, worktree.StatusError)
+			}
+			this.collectStatus(worktree.Path, worktree.Status)
+		}
+		if report.Ahead > 0 {
+			this.ahead[report.RepoPath] += report.RevListAhead()
+		}
+		if report.Behind > 0 {
+			this.behind[report.RepoPath] += report.RevListBehind()
 		}
-		if len(report.RevListAhead) > 0 {
-			this.ahead[report.RepoPath] += report.RevListAhead
+		if report.Stashes > 0 {
+			this.stashed[report.RepoPath] += report.StashMessage()
 		}
-		if len(report.RevListBehind) > 0 {
-			this.behind[report.RepoPath] += report.RevListBehind
+		if report.Operation != "" {
+			this.pending[report.RepoPath] += report.OperationMessage()
 		}
-		if len(report.SkipOutput) > 0 {
-			this.skipped[report.RepoPath] += report.SkipOutput
+		if report.Detached {
+			this.detach[report.RepoPath] += report.DetachedMessage()
 		}
-		if len(report.OmitOutput) > 0 {
-			this.omitted[report.RepoPath] += report.OmitOutput
+		if report.Bisecting {
+			this.bisects[report.RepoPath] += report.BisectMessage()
+		}
+		for _, branch := range report.Branches {
+			if branch.Ahead > 0 {
+				this.ahead[report.RepoPath] += branch.AheadMessage()
+			}
+			if branch.Behind > 0 {
+				this.behind[report.RepoPath] += branch.BehindMessage()
+			}
+			if branch.Gone {
+				this.gone[report.RepoPath] += branch.GoneMessage()
+			}
+		}
+		if report.Skipped {
+			this.skipped[report.RepoPath] += "true"
+		}
+		if report.Omitted {
+			this.omitted[report.RepoPath] += "true"
 		}
 
-		if this.config.GitFetch && len(report.FetchOutput) > 0 {
-			this.fetched[report.RepoPath] += report.FetchOutput + report.RevListOutput
+		if (this.config.GitFetch && len(report.Fetched) > 0) || len(report.Unreviewed) > 0 {
+			this.fetched[report.RepoPath] += report.ReviewLog()
 
+			if report.ForcePushed() {
+				this.forced[report.RepoPath] += report.FetchLog()
+				log.Printf("WARNING: the %s branch of %s was force-pushed!", report.Branch, report.RepoPath)
+			}
 			if this.canJournal(report) {
-				this.journal[report.RepoPath] += report.FetchOutput + report.RevListOutput
+				this.journal[report.RepoPath] += report.ReviewLog()
 			}
 		}
 	}
 }
 
+func (this *GitReviewer) collectStatus(path string, status GitStatus) {
+	if status.Messy() {
+		this.messy[path] += status.String()
+	}
+	if status.Changed() {
+		this.changed[path] += status.String()
+	}
+	if len(status.Conflicted) > 0 {
+		this.conflicted[path] += status.String()
+	}
+	if len(status.Ignored) > 0 {
+		this.ignored[path] += status.String()
+	}
+}
+
+// flattenReports lists each report followed by the reports of its submodules.
+func flattenReports(reports []*GitReport) (all []*GitReport) {
+	for _, report := range reports {
+		all = append(all, report)
+		all = append(all, flattenReports(report.Submodules)...)
+	}
+	return all
+}
+
 func (this *GitReviewer) canJournal(report *GitReport) bool {
-	if !strings.Contains(report.RemoteOutput, "smarty") { // Exclude externals from code review journal.
+	if _, found := this.omitted[report.RepoPath]; found {
 		return false
 	}
-	if _, found := this.omitted[report.RepoPath]; found {
+	switch report.Journal {
+	case "true":
+		return true
+	case "false":
 		return false
 	}
-	return true
+	return journalRulesMatch(this.config.JournalRules, report.RemoteOutput) // Exclude externals from code review journal.
 }
 
-func (this *GitReviewer) ReviewAll() {
+func (this *GitReviewer) reviewable() []string {
 	var review []map[string]string
 	if this.config.ReviewError {
 		review = append(review, this.erred)
 	}
+	if this.config.ReviewTimedOut {
+		review = append(review, this.timeout)
+	}
+	if this.config.ReviewUnfetched {
+		review = append(review, this.unfetched)
+	}
 	if this.config.ReviewMessy {
 		review = append(review, this.messy)
 	}
+	if this.config.ReviewChanged {
+		review = append(review, this.changed)
+	}
+	if this.config.ReviewConflicted {
+		review = append(review, this.conflicted)
+	}
 	if this.config.ReviewAhead {
 		review = append(review, this.ahead)
 	}
@@ -112,60 +252,253 @@ func (this *GitReviewer) ReviewAll() {
 	if this.config.ReviewFetched {
 		review = append(review, this.fetched)
 	}
+	if this.config.ReviewForced {
+		review = append(review, this.forced)
+	}
+	if this.config.ReviewGone {
+		review = append(review, this.gone)
+	}
+	if this.config.ReviewStashed {
+		review = append(review, this.stashed)
+	}
+	if this.config.ReviewPending {
+		review = append(review, this.pending)
+	}
+	if this.config.ReviewDetached {
+		review = append(review, this.detach)
+	}
+	if this.config.ReviewBisecting {
+		review = append(review, this.bisects)
+	}
 	if this.config.ReviewJournal {
 		review = append(review, this.journal)
 	}
-	reviewable := sortUniqueKeys(review...)
-	if len(reviewable) == 0 {
-		log.Println("Nothing to review at this time.")
-		return
-	}
+	return sortUniqueKeys(review...)
+}
 
+func (this *GitReviewer) printSummary(reviewable []string) {
 	printMapKeys(this.erred, "Repositories with git errors: %d")
-	printMapKeys(this.messy, "Repositories with uncommitted changes: %d")
-	printMapKeys(this.ahead, "Repositories ahead of their origin: %d")
-	printMapKeys(this.behind, "Repositories behind their origin: %d")
+	printMapKeys(this.timeout, "Repositories with git commands that timed out: %d")
+	printMapKeys(this.unfetched, "Repositories that could not be fetched (network or lock trouble): %d")
+	printMapSummaries(this.messy, "Repositories with uncommitted changes: %d")
+	printMapSummaries(this.changed, "Repositories with uncommitted changes to tracked files: %d")
+	printMapSummaries(this.conflicted, "Repositories with conflicts: %d")
+	printMapSummaries(this.ignored, "Repositories with large ignored files: %d")
+	printMapKeys(this.ahead, "Repositories ahead of their remote: %d")
+	printMapKeys(this.behind, "Repositories behind their remote: %d")
 	printMapKeys(this.fetched, "Repositories with new content since the last review: %d")
+	printMapKeys(this.forced, "Repositories with FORCE-PUSHED default branches: %d")
+	printMapKeys(this.gone, "Repositories with branches whose upstream is gone: %d")
+	printMapKeys(this.stashed, "Repositories with stash entries: %d")
+	printMapKeys(this.pending, "Repositories with an operation (rebase, merge, etc.) in progress: %d")
+	printMapKeys(this.detach, "Repositories with a detached HEAD: %d")
+	printMapKeys(this.bisects, "Repositories with a bisect in progress: %d")
 	printMapKeys(this.journal, "Repositories to be included in the final report: %d")
 	printMapKeys(this.skipped, "Repositories that were skipped: %d")
 	printStrings(reviewable, "Repositories to be reviewed: %d")
+}
 
-	in := prompt(fmt.Sprintf("Press <ENTER> to initiate the review process (will open %d review windows), or 'q' to quit...", len(reviewable)))
-	if in == "q" {
-		os.Exit(0)
+// ReviewBatch summarizes the analysis without prompting or launching any
+// GUI and returns an exit code with a bit set for each category selected
+// for review (see the -review flag) that contains any repositories.
+func (this *GitReviewer) ReviewBatch() (code int) {
+	this.printSummary(this.reviewable())
+	if this.config.ReviewError && len(this.erred) > 0 {
+		code |= exitCodeErred
+	}
+	if this.config.ReviewTimedOut && len(this.timeout) > 0 {
+		code |= exitCodeErred
+	}
+	if this.config.ReviewUnfetched && len(this.unfetched) > 0 {
+		code |= exitCodeErred
+	}
+	if this.config.ReviewMessy && len(this.messy) > 0 {
+		code |= exitCodeMessy
+	}
+	if this.config.ReviewChanged && len(this.changed) > 0 {
+		code |= exitCodeMessy
+	}
+	if this.config.ReviewConflicted && len(this.conflicted) > 0 {
+		code |= exitCodeMessy
+	}
+	if this.config.ReviewAhead && len(this.ahead) > 0 {
+		code |= exitCodeAhead
+	}
+	if this.config.ReviewBehind && len(this.behind) > 0 {
+		code |= exitCodeBehind
+	}
+	if this.config.ReviewFetched && len(this.fetched) > 0 {
+		code |= exitCodeFetched
 	}
+	if this.config.ReviewJournal && len(this.journal) > 0 {
+		code |= exitCodeFetched
+	}
+	if this.config.ReviewForced && len(this.forced) > 0 {
+		code |= exitCodeFetched
+	}
+	if this.config.ReviewGone && len(this.gone) > 0 {
+		code |= exitCodeUnfinished
+	}
+	if this.config.ReviewStashed && len(this.stashed) > 0 {
+		code |= exitCodeUnfinished
+	}
+	if this.config.ReviewPending && len(this.pending) > 0 {
+		code |= exitCodeUnfinished
+	}
+	if this.config.ReviewDetached && len(this.detach) > 0 {
+		code |= exitCodeUnfinished
+	}
+	if this.config.ReviewBisecting && len(this.bisects) > 0 {
+		code |= exitCodeUnfinished
+	}
+	log.Printf("Batch review complete (exit code: %d).", code)
+	return code
+}
 
+func (this *GitReviewer) ReviewAll() {
+	reviewable := this.reviewable()
+	this.printSummary(reviewable)
+
+	for len(reviewable) == 0 {
+		if prompt("Nothing to review at this time. Press <ENTER> to continue, or 'm' for maintenance actions...") != "m" {
+			return
+		}
+		this.MaintainAll()
+	}
+
+	for {
+		in := prompt(fmt.Sprintf("Press <ENTER> to initiate the review process (will open %d review windows), 'm' for maintenance actions, or 'q' to quit...", len(reviewable)))
+		if in == "q" {
+			os.Exit(0)
+		}
+		if in != "m" {
+			break
+		}
+		this.MaintainAll()
+	}
+
+	if this.config.GitGUILauncher == builtinGUILauncher {
+		this.reviewed = NewTerminalReview(this, reviewable).ReviewAll()
+		return
+	}
+
+	launcher := NewGUILauncher(this.config.GitGUILauncher)
+	if this.config.GUIBatchSize > 0 {
+		this.reviewed = this.reviewInBatches(reviewable, launcher, this.config.GUIBatchSize)
+		return
+	}
+	this.reviewed = reviewable
 	for _, path := range reviewable {
-		log.Printf("Opening %s at %s", this.config.GitGUILauncher, path)
-		var err error
-		if this.config.GitGUILauncher == "gitk" {
-			tmp, _ := os.Getwd()
-			_ = os.Chdir(path)
-			err = exec.Command(this.config.GitGUILauncher, "--all").Run()
-			_ = os.Chdir(tmp)
-		} else {
-			err = exec.Command(this.config.GitGUILauncher, path).Run()
-		}
-		if err != nil {
+		cmd := launcher.Command(path, this.report(path))
+		log.Printf("Opening %s at %s", strings.Join(cmd.Args, " "), path)
+		if err := cmd.Run(); err != nil {
 			log.Println("Failed to open git GUI:", err)
 		}
 		time.Sleep(time.Millisecond * 25)
+		this.signOff(path)
 	}
 }
 
-func (this *GitReviewer) PrintCodeReviewLogEntry() {
-	if len(this.journal) == 0 {
-		return
+// ReviewJournal considers the repositories included in the code review log
+// entry reviewed (so that their review markers are recorded), as the journal
+// command records a review conducted by some other means.
+func (this *GitReviewer) ReviewJournal() {
+	this.reviewed = mapKeys(this.journal)
+}
+
+// PrintCodeReviewLogEntry concludes the review (unless the reviewer quits
+// instead) by recording the review markers and printing the code review log
+// entry, and reports whether the review was concluded.
+func (this *GitReviewer) PrintCodeReviewLogEntry() (concluded bool) {
+	markers := this.reviewMarkers()
+	if len(this.journal) == 0 && len(markers) == 0 {
+		return true
+	}
+
+	in := prompt("Press <ENTER> to conclude review process and print code review log entry, or 'q' to quit without recording the review...")
+	if in == "q" {
+		return false
 	}
 
-	prompt("Press <ENTER> to conclude review process and print code review log entry...")
+	this.advanceReviewMarkers(markers)
+
+	if len(this.journal) == 0 {
+		return true
+	}
 
 	writer := this.config.OpenOutputWriter()
 	defer func() { _ = writer.Close() }()
 
-	_, _ = fmt.Fprintf(writer, "\n\n##%s\n\n", time.Now().Format("2006-01-02"))
-	for _, review := range this.journal {
-		_, _ = fmt.Fprintln(writer, excludeSSHFingerprints(review))
+	renderer := NewJournalRenderer(this.config.JournalFormat)
+	if err := renderer.Render(writer, time.Now(), this.journalEntries()); err != nil {
+		log.Println("Could not write code review log entry:", err)
+	}
+	return true
+}
+
+func (this *GitReviewer) report(path string) *GitReport {
+	for _, report := range flattenReports(this.reports) {
+		if report.RepoPath == path {
+			return report
+		}
+	}
+	return nil
+}
+
+// findings lists what was learned about the repository at path during analysis.
+func (this *GitReviewer) findings(path string) (findings []string) {
+	for _, category := range []struct {
+		label    string
+		findings map[string]string
+	}{
+		{"Errors:", this.erred},
+		{"Timed out:", this.timeout},
+		{"Not fetched:", this.unfetched},
+		{"Uncommitted changes:", this.messy},
+		{"Ahead:", this.ahead},
+		{"Behind:", this.behind},
+		{"FORCE-PUSHED:", this.forced},
+		{"Upstream gone:", this.gone},
+		{"Stashed:", this.stashed},
+		{"In progress:", this.pending},
+		{"Detached:", this.detach},
+		{"Bisecting:", this.bisects},
+		{"New content:", this.fetched},
+	} {
+		if finding, found := category.findings[path]; found {
+			findings = append(findings, category.label+"\n"+excludeSSHFingerprints(finding))
+		}
+	}
+	return findings
+}
+
+// reviewMarkers lists the reports of reviewed repositories whose review marker
+// is behind (or, lacking a marker, without one at) the remote commit observed
+// during analysis.
+func (this *GitReviewer) reviewMarkers() (reports []*GitReport) {
+	reviewed := make(map[string]struct{}, len(this.reviewed))
+	for _, path := range this.reviewed {
+		reviewed[path] = struct{}{}
+	}
+	for _, report := range flattenReports(this.reports) {
+		if _, found := reviewed[report.RepoPath]; !found {
+			continue
+		}
+		if _, found := this.fetched[report.RepoPath]; !found {
+			continue
+		}
+		if report.RemoteCommit != "" && report.RemoteCommit != report.ReviewedCommit {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+func (this *GitReviewer) advanceReviewMarkers(reports []*GitReport) {
+	for _, report := range reports {
+		if err := report.AdvanceReviewMarker(); err != nil {
+			log.Printf("Could not record review marker for %s: %v", report.RepoPath, err)
+		}
 	}
 }
 
@@ -206,4 +539,17 @@ func excludeSSHFingerprints(report string) string {
 	return b.String()
 }
 
-const workerCount = 16
+var errAnalysisDeadline = errors.New("analysis ran past -deadline")
+
+// The exit codes of -batch leave the two lowest bits clear, as those are set
+// when gitreview fails: log.Fatal exits with 1, invalid flags with 2 and an
+// interrupt with 130. Exit codes are limited to 8 bits, leaving room for 6
+// categories.
+const (
+	exitCodeErred      = 4 << iota // (e), (t) or (u)
+	exitCodeMessy                  // (m), (w) or (c)
+	exitCodeAhead                  // (a)
+	exitCodeBehind                 // (b)
+	exitCodeFetched                // (f), (j) or (x)
+	exitCodeUnfinished             // (g), (z), (p), (d) or (i)
+)
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	signOffApproved  = "approved"
+	signOffFlagged   = "flagged"
+	signOffFollowUp  = "follow-up"
+	signOffDecisions = "(a)pprove, (f)lag, (u) needs follow-up, or <ENTER> to skip; optionally followed by a note"
+)
+
+// SignOff is the reviewer's decision regarding a single commit.
+type SignOff struct {
+	Commit   GitCommitSummary
+	Decision string
+	Note     string
+}
+
+// signOff asks the reviewer to record a decision for each commit under review
+// in the repository at path. Decisions are included in the code review log entry.
+func (this *GitReviewer) signOff(path string) {
+	if !this.config.SignOff {
+		return
+	}
+	report := this.report(path)
+	if report == nil {
+		return
+	}
+	commits, err := report.GitCommitSummaries(report.ReviewCommits())
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	if len(commits) > 0 {
+		log.Printf("Sign off on %d commit(s) at %s:", len(commits), path)
+	}
+
+	var signOffs []SignOff
+	for _, commit := range commits {
+		for {
+			decision, note, ok := parseSignOff(prompt(fmt.Sprintf("  %s %s -- %s:", commit.ID, commit.Subject, signOffDecisions)))
+			if !ok {
+				continue
+			}
+			if decision != "" {
+				signOffs = append(signOffs, SignOff{Commit: commit, Decision: decision, Note: note})
+			}
+			break
+		}
+	}
+	this.signOffs[path] = signOffs
+}
+
+func parseSignOff(input string) (decision, note string, ok bool) {
+	letter, note, _ := strings.Cut(strings.TrimSpace(input), " ")
+	switch strings.ToLower(letter) {
+	case "":
+		return "", "", true
+	case "a":
+		decision = signOffApproved
+	case "f":
+		decision = signOffFlagged
+	case "u":
+		decision = signOffFollowUp
+	default:
+		return "", "", false
+	}
+	return decision, strings.TrimSpace(note), true
+}
+
+func formatSignOffs(signOffs []SignOff) string {
+	if len(signOffs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Sign-off:\n")
+	for _, signOff := range signOffs {
+		line := fmt.Sprintf("  %-11s %s %s (%s)", "["+signOff.Decision+"]", signOff.Commit.ID, signOff.Commit.Subject, signOff.Commit.Author)
+		if signOff.Note != "" {
+			line += " -- " + signOff.Note
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
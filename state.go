@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	gitStashListCommand = "git stash list"                // 1 line per stash entry
+	gitDirCommand       = "git rev-parse --git-dir"       // ie. [.git]
+	gitSymbolicHead     = "git symbolic-ref --quiet HEAD" // fails when HEAD is detached
+)
+
+// gitOperations lists, in order of precedence, the files (or directories) in
+// the git directory whose presence indicates an operation in progress.
+var gitOperations = []struct{ path, operation string }{
+	{"rebase-merge", "rebase"},
+	{filepath.Join("rebase-apply", "applying"), "am"},
+	{"rebase-apply", "rebase"},
+	{"MERGE_HEAD", "merge"},
+	{"CHERRY_PICK_HEAD", "cherry-pick"},
+	{"REVERT_HEAD", "revert"},
+}
+
+// GitState finds the states of a repository that 'git status --porcelain'
+// doesn't mention: stashes, an operation in progress, a detached HEAD and
+// a bisect in progress.
+func (this *GitReport) GitState(ctx context.Context) {
+	out, err := execute(ctx, this.RepoPath, gitStashListCommand)
+	if err != nil {
+		this.StatusError += this.failed(gitStashListCommand, err)
+	} else if out = strings.TrimSpace(out); out != "" {
+		this.Stashes = len(strings.Split(out, "\n"))
+	}
+
+	out, err = execute(ctx, this.RepoPath, gitDirCommand)
+	if err != nil {
+		this.StatusError += this.failed(gitDirCommand, err)
+		return
+	}
+	gitDir := strings.TrimSpace(out)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(this.RepoPath, gitDir)
+	}
+	for _, candidate := range gitOperations {
+		if exists(filepath.Join(gitDir, candidate.path)) {
+			this.Operation = candidate.operation
+			break
+		}
+	}
+	this.Bisecting = exists(filepath.Join(gitDir, "BISECT_LOG"))
+
+	if this.Operation == "" && !this.Bisecting { // HEAD is expected to be detached during a rebase, bisect, etc.
+		_, err = execute(ctx, this.RepoPath, gitSymbolicHead)
+		this.Detached = err != nil
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (this *GitReport) StashMessage() string {
+	return fmt.Sprintf("There are %d stash entries.\n", this.Stashes)
+}
+
+func (this *GitReport) OperationMessage() string {
+	return fmt.Sprintf("A %s is in progress.\n", this.Operation)
+}
+
+func (this *GitReport) DetachedMessage() string {
+	return "HEAD is detached.\n"
+}
+
+func (this *GitReport) BisectMessage() string {
+	return "A bisect is in progress.\n"
+}
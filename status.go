@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	gitStatusCommand        = "git --no-optional-locks status --porcelain=v2 --branch -uall"                       // parse-able output, including untracked (without taking index.lock)
+	gitStatusIgnoredCommand = "git --no-optional-locks status --porcelain=v2 --branch -uall --ignored=traditional" // ...and ignored
+)
+
+// GitStatus is the parsed output of 'git status --porcelain=v2 --branch'.
+type GitStatus struct {
+	Head     string `json:"head,omitempty"` // ie. master or (detached)
+	Upstream string `json:"upstream,omitempty"`
+
+	Staged     []string `json:"staged,omitempty"`
+	Unstaged   []string `json:"unstaged,omitempty"`
+	Untracked  []string `json:"untracked,omitempty"`
+	Conflicted []string `json:"conflicted,omitempty"`
+	Ignored    []string `json:"ignored,omitempty"` // only those at least as large as -ignored-size
+}
+
+// gitStatusCommandFor examines ignored files only when ignoredSize (in bytes)
+// is positive.
+func gitStatusCommandFor(ignoredSize int64) string {
+	if ignoredSize > 0 {
+		return gitStatusIgnoredCommand
+	}
+	return gitStatusCommand
+}
+
+// gitStatus runs 'git status' in dir, returning the error of the command as is.
+func gitStatus(ctx context.Context, dir string, ignoredSize int64) (status GitStatus, err error) {
+	out, err := execute(ctx, dir, gitStatusCommandFor(ignoredSize))
+	if err != nil {
+		return status, err
+	}
+	status = parseGitStatus(out)
+	status.Ignored = largeFiles(dir, status.Ignored, ignoredSize)
+	return status, nil
+}
+
+// parseGitStatus interprets each line of the porcelain v2 format:
+//
+//	# branch.head <branch>
+//	# branch.upstream <upstream>
+//	1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+//	2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path><tab><origPath>
+//	u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+//	? <path>
+//	! <path>
+//
+// where X and Y are the staged and unstaged status ('.' when unmodified).
+func parseGitStatus(out string) (status GitStatus) {
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 3 {
+			continue
+		}
+		switch line[0] {
+		case '#':
+			key, value, _ := strings.Cut(line[2:], " ")
+			switch key {
+			case "branch.head":
+				status.Head = value
+			case "branch.upstream":
+				status.Upstream = value
+			}
+		case '1', '2':
+			fields := strings.SplitN(line, " ", 9+int(line[0]-'1'))
+			if len(fields) < 9 {
+				continue
+			}
+			path, _, _ := strings.Cut(fields[len(fields)-1], "\t")
+			if fields[1][0] != '.' {
+				status.Staged = append(status.Staged, path)
+			}
+			if fields[1][1] != '.' {
+				status.Unstaged = append(status.Unstaged, path)
+			}
+		case 'u':
+			fields := strings.SplitN(line, " ", 11)
+			if len(fields) == 11 {
+				status.Conflicted = append(status.Conflicted, fields[10])
+			}
+		case '?':
+			status.Untracked = append(status.Untracked, line[2:])
+		case '!':
+			status.Ignored = append(status.Ignored, line[2:])
+		}
+	}
+	return status
+}
+
+func largeFiles(dir string, paths []string, size int64) (large []string) {
+	if size <= 0 {
+		return nil
+	}
+	for _, path := range paths {
+		stat, err := os.Stat(filepath.Join(dir, path))
+		if err == nil && !stat.IsDir() && stat.Size() >= size {
+			large = append(large, path)
+		}
+	}
+	return large
+}
+
+// Messy reports uncommitted changes of any kind (ignored files excepted).
+func (this GitStatus) Messy() bool {
+	return len(this.Staged)+len(this.Unstaged)+len(this.Untracked)+len(this.Conflicted) > 0
+}
+
+// Changed reports uncommitted changes to tracked files (or conflicts).
+func (this GitStatus) Changed() bool {
+	return len(this.Staged)+len(this.Unstaged)+len(this.Conflicted) > 0
+}
+
+func (this GitStatus) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "staged: %d, unstaged: %d, untracked: %d, conflicted: %d, large ignored: %d\n",
+		len(this.Staged), len(this.Unstaged), len(this.Untracked), len(this.Conflicted), len(this.Ignored))
+	for _, group := range []struct {
+		label string
+		paths []string
+	}{
+		{"C", this.Conflicted},
+		{"S", this.Staged},
+		{"U", this.Unstaged},
+		{"?", this.Untracked},
+		{"!", this.Ignored},
+	} {
+		for _, path := range group.paths {
+			b.WriteString("  " + group.label + " " + path + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGitStatus(t *testing.T) {
+	const hash = "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"
+	for _, test := range []struct {
+		name string
+		out  string
+		want GitStatus
+	}{
+		{
+			name: "clean",
+			out:  "# branch.oid " + hash + "\n# branch.head master\n# branch.upstream origin/master\n# branch.ab +0 -0\n",
+			want: GitStatus{Head: "master", Upstream: "origin/master"},
+		},
+		{
+			name: "detached",
+			out:  "# branch.oid " + hash + "\n# branch.head (detached)\n",
+			want: GitStatus{Head: "(detached)"},
+		},
+		{
+			name: "ordinary changes",
+			out: "# branch.head master\n" +
+				"1 M. N... 100644 100644 100644 " + hash + " " + hash + " staged.go\n" +
+				"1 .M N... 100644 100644 100644 " + hash + " " + hash + " dir/with space.go\n" +
+				"1 MM N... 100644 100644 100644 " + hash + " " + hash + " both.go\n",
+			want: GitStatus{
+				Head:     "master",
+				Staged:   []string{"staged.go", "both.go"},
+				Unstaged: []string{"dir/with space.go", "both.go"},
+			},
+		},
+		{
+			name: "renamed",
+			out: "# branch.head master\n" +
+				"2 R. N... 100644 100644 100644 " + hash + " " + hash + " R100 new name.go\told name.go\n" +
+				"2 RM N... 100644 100644 100644 " + hash + " " + hash + " R087 edited.go\toriginal.go\n",
+			want: GitStatus{
+				Head:     "master",
+				Staged:   []string{"new name.go", "edited.go"},
+				Unstaged: []string{"edited.go"},
+			},
+		},
+		{
+			name: "conflicted",
+			out: "# branch.head master\n" +
+				"u UU N... 100644 100644 100644 100644 " + hash + " " + hash + " " + hash + " conflict.go\n" +
+				"u AA N... 000000 100644 100644 100644 " + hash + " " + hash + " " + hash + " both added.go\n",
+			want: GitStatus{
+				Head:       "master",
+				Conflicted: []string{"conflict.go", "both added.go"},
+			},
+		},
+		{
+			name: "untracked and ignored",
+			out:  "# branch.head master\n? new.go\n? dir/other file.go\n! bin/app\n",
+			want: GitStatus{
+				Head:      "master",
+				Untracked: []string{"new.go", "dir/other file.go"},
+				Ignored:   []string{"bin/app"},
+			},
+		},
+		{
+			name: "truncated lines are ignored",
+			out:  "1 M. N...\n2 R. N... 100644\nu UU N...\n?\n",
+			want: GitStatus{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseGitStatus(test.out); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseGitStatus() =\n%#v\nwant\n%#v", got, test.want)
+			}
+		})
+	}
+}
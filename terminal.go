@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const builtinGUILauncher = "builtin" // -gui value selecting the terminal review below
+
+var gitCommitDiffCommand = "git show --stat --patch --no-color %s"
+
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiCyan   = "\033[36m"
+)
+
+// TerminalReview presents each repository (its findings and the commits to
+// review) on the terminal and pages through the diff of any commit, all
+// without the help of an external program. Like the prompts, it writes to
+// stderr, as stdout carries the code review log entry (and any report).
+type TerminalReview struct {
+	reviewer *GitReviewer
+	paths    []string
+	pageSize int
+	colors   bool
+}
+
+func NewTerminalReview(reviewer *GitReviewer, paths []string) *TerminalReview {
+	pageSize, _ := strconv.Atoi(os.Getenv("LINES"))
+	if pageSize < 10 {
+		pageSize = 40
+	}
+	return &TerminalReview{
+		reviewer: reviewer,
+		paths:    paths,
+		pageSize: pageSize - 2,
+		colors:   os.Getenv("NO_COLOR") == "",
+	}
+}
+
+// ReviewAll presents the repositories one at a time and returns those
+// presented before the review was concluded (or quit).
+func (this *TerminalReview) ReviewAll() (reviewed []string) {
+	visited := make(map[string]bool)
+review:
+	for i := 0; i < len(this.paths); {
+		visited[this.paths[i]] = true
+		command := this.review(i)
+		if command == "" || command == "n" {
+			this.reviewer.signOff(this.paths[i])
+		}
+		switch command {
+		case "p":
+			if i > 0 {
+				i--
+			}
+		case "q":
+			break review
+		default:
+			i++
+		}
+	}
+
+	for _, path := range this.paths {
+		if visited[path] {
+			reviewed = append(reviewed, path)
+		}
+	}
+	return reviewed
+}
+
+// review presents the repository at index i until the reviewer moves on,
+// returning the command which ended the review of the repository.
+func (this *TerminalReview) review(i int) string {
+	path := this.paths[i]
+	commits := this.commits(path)
+	for {
+		this.printf(ansiBold, "\n==> [%d/%d] %s\n", i+1, len(this.paths), path)
+		for _, finding := range this.reviewer.findings(path) {
+			fmt.Fprintln(os.Stderr, strings.TrimRight(finding, "\n"))
+		}
+		if len(commits) > 0 {
+			fmt.Fprintln(os.Stderr)
+		}
+		for n, commit := range commits {
+			fmt.Fprintf(os.Stderr, "%3d) ", n+1)
+			this.printf(ansiYellow, "%s", commit.ID)
+			fmt.Fprintf(os.Stderr, "  %s  (%s, %s)\n", commit.Subject, commit.Author, commit.Age)
+		}
+
+		command := this.read("\n[1-%d] show commit, (n)ext repository, (p)revious repository, (q)uit: ", len(commits))
+		switch command {
+		case "", "n", "p", "q":
+			return command
+		}
+		n, err := strconv.Atoi(command)
+		if err != nil || n < 1 || n > len(commits) {
+			continue
+		}
+		this.page(path, commits[n-1].ID)
+	}
+}
+
+// commits lists the id and a one-line summary of each commit to review.
+func (this *TerminalReview) commits(path string) []GitCommitSummary {
+	report := this.reviewer.report(path)
+	if report == nil {
+		return nil
+	}
+	commits, err := report.GitCommitSummaries(report.ReviewCommits())
+	if err != nil {
+		fmt.Fprint(os.Stderr, err)
+	}
+	return commits
+}
+
+func (this *TerminalReview) page(path, commit string) {
+	command := fmt.Sprintf(gitCommitDiffCommand, commit)
+	out, err := execute(context.Background(), path, command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, gitErrorTemplate, command, err)
+		return
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	for start := 0; start < len(lines); start += this.pageSize {
+		end := min(start+this.pageSize, len(lines))
+		for _, line := range lines[start:end] {
+			this.printf(this.diffColor(line), "%s\n", line)
+		}
+		if end == len(lines) {
+			break
+		}
+		if this.read("-- %d/%d lines: <ENTER> for more, (q) to return to the commit list --", end, len(lines)) == "q" {
+			return
+		}
+	}
+	this.read("-- end of commit %s: <ENTER> to return to the commit list --", commit)
+}
+
+func (this *TerminalReview) diffColor(line string) string {
+	switch {
+	case strings.HasPrefix(line, "commit "):
+		return ansiYellow
+	case strings.HasPrefix(line, "diff --git"),
+		strings.HasPrefix(line, "index "),
+		strings.HasPrefix(line, "+++ "),
+		strings.HasPrefix(line, "--- "):
+		return ansiBold
+	case strings.HasPrefix(line, "@@"):
+		return ansiCyan
+	case strings.HasPrefix(line, "+"):
+		return ansiGreen
+	case strings.HasPrefix(line, "-"):
+		return ansiRed
+	}
+	return ""
+}
+
+func (this *TerminalReview) printf(color, format string, args ...any) {
+	if this.colors && color != "" {
+		format = color + format
+		if strings.HasSuffix(format, "\n") {
+			format = strings.TrimSuffix(format, "\n") + ansiReset + "\n"
+		} else {
+			format += ansiReset
+		}
+	}
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+func (this *TerminalReview) read(format string, args ...any) string {
+	fmt.Fprintf(os.Stderr, format, args...)
+	return readLine()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var gitFastForwardCommand = "git merge --ff-only %s" // to the reviewed commit of <remote>/<default-branch>
+
+// fastForwardBlocker explains why the default branch must not be
+// fast-forwarded (blank when it may be) according to the analysis.
+func (this *GitReport) fastForwardBlocker() string {
+	switch {
+	case this.StatusError != "" || this.RevListError != "":
+		return "git errors"
+	case this.Behind == 0:
+		return "not behind"
+	case this.Ahead > 0:
+		return "ahead (diverged)"
+	case this.Detached:
+		return "detached HEAD"
+	case this.Operation != "":
+		return this.Operation + " in progress"
+	case this.Bisecting:
+		return "bisect in progress"
+	case this.Status.Messy():
+		return "uncommitted changes"
+	case this.Status.Head != this.Branch:
+		return fmt.Sprintf("on %s instead of %s", this.Status.Head, this.Branch)
+	}
+	return ""
+}
+
+// FastForward updates the (checked out) default branch to the remote commit
+// observed during analysis, which is what was reviewed. The status is checked
+// again first as the repository may have changed since the analysis.
+func (this *GitReport) FastForward(ctx context.Context) error {
+	if blocker := this.fastForwardBlocker(); blocker != "" {
+		return fmt.Errorf("not fast-forwarded: %s", blocker)
+	}
+	status, err := gitStatus(ctx, this.RepoPath, 0)
+	if err != nil {
+		return err
+	}
+	if status.Messy() || status.Head != this.Branch {
+		return fmt.Errorf("not fast-forwarded: changed since the analysis")
+	}
+	target := this.RemoteCommit
+	if target == "" {
+		target = this.RemoteBranch()
+	}
+	command := fmt.Sprintf(gitFastForwardCommand, target)
+	out, err := execute(ctx, this.RepoPath, command)
+	if err != nil {
+		return fmt.Errorf(gitErrorTemplate+"%s", command, err, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// FastForwardAll fast-forwards the default branch of each reviewed repository
+// that is behind, unless it is messy, ahead (diverged), detached or otherwise
+// busy (see fastForwardBlocker), and reports those that weren't.
+func (this *GitReviewer) FastForwardAll() {
+	var updated, failed []string
+	for _, path := range this.reviewed {
+		report := this.report(path)
+		if _, behind := this.behind[path]; !behind || report == nil || report.Behind == 0 {
+			continue
+		}
+		if err := report.FastForward(context.Background()); err != nil {
+			log.Println(path, err)
+			failed = append(failed, path)
+			continue
+		}
+		log.Printf("Fast-forwarded %s to %s.", path, report.RemoteBranch())
+		updated = append(updated, path)
+	}
+	printStrings(updated, "Repositories fast-forwarded: %d")
+	printStrings(failed, "Repositories that could not be fast-forwarded: %d")
+}
@@ -1,37 +1,67 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"path/filepath"
 )
 
 type Worker struct {
-	id  int
-	in  chan string
-	out chan *GitReport
+	id       int
+	in       chan string
+	out      chan *GitReport
+	throttle *FetchThrottle
+
+	config *Config
 }
 
-func NewWorker(id int, in chan string, out chan *GitReport) *Worker {
-	return &Worker{id: id, in: in, out: out}
+func NewWorker(id int, in chan string, out chan *GitReport, throttle *FetchThrottle, config *Config) *Worker {
+	return &Worker{id: id, in: in, out: out, throttle: throttle, config: config}
 }
 
-func (this *Worker) Start() {
+func (this *Worker) Start(ctx context.Context) {
 	for path := range this.in {
-		this.out <- this.git(path)
+		this.out <- this.git(ctx, path)
 	}
 	close(this.out)
 }
 
-func (this *Worker) git(path string) *GitReport {
+func (this *Worker) git(ctx context.Context, path string) *GitReport {
 	path, _ = filepath.Abs(path)
-	report := &GitReport{RepoPath: path}
-	if !report.GitSkipStatus() {
-		report.GitOmitStatus()
-		report.GitRemote()
-		report.GitStatus()
-		report.GitFetch()
-		report.GitRevList()
+	report := &GitReport{RepoPath: path, overrides: this.config.RepositoryOverrides(path)}
+	if ctx.Err() != nil {
+		report.TimedOut = true
+		report.StatusError = fmt.Sprintf("[ERROR] Not analyzed: %v\n", context.Cause(ctx))
+		log.Println(report.Progress())
+		return report
+	}
+	skipped := report.GitSkipStatus(ctx)
+	if !skipped {
+		report.GitOmitStatus(ctx)
+		report.GitJournalStatus(ctx)
+		report.GitRemote(ctx)
+		report.GitStatus(ctx, this.config.IgnoredFileSize)
+		report.GitState(ctx)
+		report.GitWorktrees(ctx, this.config.IgnoredFileSize)
+		report.GitBranch(ctx)
+		release := this.throttle.Acquire(ctx, ParseGitRemoteURL(report.RemoteOutput).Host)
+		report.GitFetch(ctx, this.config.GitBranches, this.config.FetchRetries)
+		release()
+		report.GitRevList(ctx)
+		report.GitReviewMarker(ctx)
+		if this.config.GitBranches {
+			report.GitBranches(ctx)
+		}
 	}
 	log.Println(report.Progress())
+	for _, worktree := range report.Worktrees {
+		log.Println(worktree.Progress())
+	}
+	if this.config.GitSubmodules && !skipped {
+		for _, submodule := range report.GitSubmodules(ctx) {
+			report.Submodules = append(report.Submodules, this.git(ctx, submodule))
+		}
+	}
 	return report
 }